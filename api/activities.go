@@ -0,0 +1,118 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// createActivity handles POST /activities.
+// Expects a JSON body matching models.Activity (without id/created_at/updated_at).
+func (h *handler) createActivity(ctx *gin.Context) {
+	var activity models.Activity
+
+	if err := ctx.ShouldBindJSON(&activity); err != nil {
+		respondBindError(ctx, "request body", err)
+		return
+	}
+
+	created, err := h.svc.CreateActivity(ctx.Request.Context(), activity)
+	if err != nil {
+		respondError(ctx, "", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, created)
+}
+
+// getActivity handles GET /activities/:id.
+// Accepts an optional currency query param to add converted prices.
+func (h *handler) getActivity(ctx *gin.Context) {
+	currency, ok := bindCurrency(ctx)
+	if !ok {
+		return
+	}
+
+	id := ctx.Param("id")
+
+	activity, err := h.svc.GetActivity(ctx.Request.Context(), id)
+	if err != nil {
+		respondError(ctx, "activity not found", err)
+		return
+	}
+
+	if !h.convertPrices(ctx, currency, activityPrices(activity)) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, activity)
+}
+
+// listActivities handles GET /activities.
+// Accepts optional query params: location (partial, case-insensitive),
+// date (YYYY-MM-DD), min_price and max_price, currency for converted
+// prices, plus the shared pagination params.
+func (h *handler) listActivities(ctx *gin.Context) {
+	currency, ok := bindCurrency(ctx)
+	if !ok {
+		return
+	}
+
+	var params models.ActivitySearchParams
+
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		respondBindError(ctx, "query parameters", err)
+		return
+	}
+
+	page, ok := bindPage(ctx)
+	if !ok {
+		return
+	}
+
+	activities, err := h.svc.ListActivities(ctx.Request.Context(), params, page)
+	if err != nil {
+		respondError(ctx, "", err)
+		return
+	}
+
+	if !h.convertPrices(ctx, currency, activityPrices(pointers(activities.Items)...)) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, activities)
+}
+
+// updateActivity handles PUT /activities/:id.
+// Accepts a partial JSON body; only provided fields are updated.
+func (h *handler) updateActivity(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	var req models.UpdateActivityRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondBindError(ctx, "request body", err)
+		return
+	}
+
+	updated, err := h.svc.UpdateActivity(ctx.Request.Context(), id, req)
+	if err != nil {
+		respondError(ctx, "activity not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, updated)
+}
+
+// deleteActivity handles DELETE /activities/:id.
+func (h *handler) deleteActivity(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	if err := h.svc.DeleteActivity(ctx.Request.Context(), id); err != nil {
+		respondError(ctx, "activity not found", err)
+		return
+	}
+
+	// 204 No Content — successful deletion with no body.
+	ctx.Status(http.StatusNoContent)
+}
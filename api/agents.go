@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// assignClient handles POST /agents/:id/clients.
+// Expects a JSON body matching models.AssignClientRequest.
+func (h *handler) assignClient(ctx *gin.Context) {
+	agentID := ctx.Param("id")
+
+	var req models.AssignClientRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondBindError(ctx, "request body", err)
+		return
+	}
+
+	assignment, err := h.svc.AssignClient(ctx.Request.Context(), agentID, req.ClientID)
+	if err != nil {
+		respondError(ctx, "", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, assignment)
+}
+
+// listClients handles GET /agents/:id/clients.
+// Agents may list their own clients; anyone else needs agents.manage.
+func (h *handler) listClients(ctx *gin.Context) {
+	agentID := ctx.Param("id")
+
+	actor := currentActor(ctx)
+	if actor.UserID != agentID && !actor.Can(models.PermissionManageAgents) {
+		abortWithError(ctx, http.StatusForbidden, models.ErrorCodeForbidden, "permission \""+string(models.PermissionManageAgents)+"\" required")
+		return
+	}
+
+	clientIDs, err := h.svc.ListClients(ctx.Request.Context(), agentID)
+	if err != nil {
+		respondError(ctx, "", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, clientIDs)
+}
+
+// unassignClient handles DELETE /agents/:id/clients/:client_id.
+func (h *handler) unassignClient(ctx *gin.Context) {
+	agentID := ctx.Param("id")
+	clientID := ctx.Param("client_id")
+
+	if err := h.svc.UnassignClient(ctx.Request.Context(), agentID, clientID); err != nil {
+		respondError(ctx, "assignment not found", err)
+		return
+	}
+
+	// 204 No Content — successful deletion with no body.
+	ctx.Status(http.StatusNoContent)
+}
@@ -1,13 +1,30 @@
 package api
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
 	"github.com/namkatcedrickjumtock/travel-planner/internal/services"
 )
 
+// Config carries the settings the API layer needs beyond the service itself.
+type Config struct {
+	// JWTSecret is the HS256 key used to verify bearer tokens.
+	JWTSecret string
+
+	// Roles maps token roles to permissions. Nil means DefaultRolePermissions.
+	Roles RolePermissions
+
+	// RequestTimeout bounds how long a request, including its database
+	// queries, may run. Zero disables the deadline.
+	RequestTimeout time.Duration
+}
+
 // handler holds a reference to the service layer, shared by all route files.
 type handler struct {
 	svc services.Planner
@@ -15,50 +32,163 @@ type handler struct {
 
 // NewAPIListener wires up the Gin router with all routes and middleware,
 // then returns the engine ready to call Run() on.
-func NewAPIListener(svc services.Planner) (*gin.Engine, error) {
+func NewAPIListener(svc services.Planner, cfg Config) (*gin.Engine, error) {
+	if cfg.JWTSecret == "" {
+		return nil, fmt.Errorf("api: jwt secret must not be empty")
+	}
+
+	useRequestFieldNames()
+
 	router := gin.Default()
 
 	// CORS middleware — allows all origins in development.
 	// Swap cors.Default() for a custom cors.Config in production.
 	router.Use(cors.Default())
 
+	// Per-request deadline, propagated down to every Postgres query.
+	if cfg.RequestTimeout > 0 {
+		router.Use(withTimeout(cfg.RequestTimeout))
+	}
+
 	h := &handler{svc: svc}
 
+	if cfg.Roles == nil {
+		cfg.Roles = DefaultRolePermissions()
+	}
+
+	// Trips and bookings are private to their members and need a caller identity.
+	requireAuth := authenticate([]byte(cfg.JWTSecret), cfg.Roles)
+
+	// Catalogue reads are public; writes are limited to admins and suppliers.
+	writeCatalogue := []gin.HandlerFunc{requireAuth, requirePermission(models.PermissionCatalogueWrite)}
+
 	// Health-check — useful for load balancers and container orchestrators.
 	router.GET("/health", func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 
 	// ── Trips ────────────────────────────────────────────────────────────────
-	trips := router.Group("/trips")
+	// Creating trips and bookings accepts an Idempotency-Key so that clients
+	// can retry them safely.
+	trips := router.Group("/trips", requireAuth)
 	{
-		trips.POST("", h.createTrip)
+		trips.POST("", h.idempotent, h.createTrip)
 		trips.GET("", h.listTrips)
 		trips.GET("/search", h.searchTrips) // must come before /:id to avoid shadowing
 		trips.GET("/:id", h.getTrip)
+		trips.GET("/:id/summary", h.getTripSummary)
+		trips.GET("/:id/itinerary", h.getTripItinerary)
+		trips.GET("/:id/conflicts", h.getTripConflicts)
+		trips.GET("/:id/history", h.getTripHistory)
 		trips.PUT("/:id", h.updateTrip)
 		trips.DELETE("/:id", h.deleteTrip)
+		trips.POST("/:id/restore", h.restoreTrip)
 
 		// Bookings nested under their parent trip.
-		trips.POST("/:id/bookings", h.bookItem)
+		trips.POST("/:id/bookings", h.idempotent, h.bookItem)
 		trips.GET("/:id/bookings", h.getTripBookings)
+
+		// Shared expenses and who owes whom.
+		trips.POST("/:id/expenses", h.recordExpense)
+		trips.GET("/:id/expenses", h.listExpenses)
+		trips.DELETE("/:id/expenses/:expense_id", h.deleteExpense)
+		trips.GET("/:id/balances", h.getTripBalances)
+
+		// Members and invitations; owners manage them, invitees accept.
+		trips.GET("/:id/members", h.listTripMembers)
+		trips.DELETE("/:id/members/:user_id", h.removeTripMember)
+		trips.POST("/:id/members/invitations", h.inviteTripMember)
+		trips.GET("/:id/members/invitations", h.listTripInvitations)
+		trips.DELETE("/:id/members/invitations/:invitation_id", h.revokeTripInvitation)
+		trips.POST("/:id/members/invitations/:invitation_id/accept", h.acceptTripInvitation)
+	}
+
+	// Pending invitations addressed to the caller.
+	router.GET("/invitations", requireAuth, h.listMyInvitations)
+
+	// ── Bookings ─────────────────────────────────────────────────────────────
+	bookings := router.Group("/bookings", requireAuth)
+	{
+		bookings.GET("/:id", h.getBooking)
+		bookings.GET("/:id/transitions", h.getBookingTransitions)
+		bookings.POST("/:id/confirm", h.confirmBooking)
+		bookings.POST("/:id/cancel", h.cancelBooking)
 	}
 
 	// ── Hotels ───────────────────────────────────────────────────────────────
 	hotels := router.Group("/hotels")
 	{
-		hotels.POST("", h.createHotel)
+		hotels.POST("", append(writeCatalogue, h.createHotel)...)
 		hotels.GET("", h.listHotels)
 		hotels.GET("/:id", h.getHotel)
+		hotels.POST("/:id/room-types", append(writeCatalogue, h.createRoomType)...)
+		hotels.GET("/:id/room-types", h.listRoomTypes)
 	}
 
 	// ── Flights ──────────────────────────────────────────────────────────────
 	flights := router.Group("/flights")
 	{
-		flights.POST("", h.createFlight)
+		flights.POST("", append(writeCatalogue, h.createFlight)...)
 		flights.GET("", h.listFlights)
 		flights.GET("/:id", h.getFlight)
 	}
 
+	// ── Activities ───────────────────────────────────────────────────────────
+	activities := router.Group("/activities")
+	{
+		activities.POST("", append(writeCatalogue, h.createActivity)...)
+		activities.GET("", h.listActivities)
+		activities.GET("/:id", h.getActivity)
+		activities.PUT("/:id", append(writeCatalogue, h.updateActivity)...)
+		activities.DELETE("/:id", append(writeCatalogue, h.deleteActivity)...)
+	}
+
+	// ── Agents ───────────────────────────────────────────────────────────────
+	agents := router.Group("/agents/:id/clients", requireAuth)
+	{
+		manageAgents := requirePermission(models.PermissionManageAgents)
+
+		agents.POST("", manageAgents, h.assignClient)
+		agents.GET("", h.listClients) // agents may list their own clients
+		agents.DELETE("/:client_id", manageAgents, h.unassignClient)
+	}
+
+	// ── Travellers ───────────────────────────────────────────────────────────
+	// A user's travellers are managed by whoever may manage the user's trips.
+	travellers := router.Group("/users/:id/travellers", requireAuth)
+	{
+		travellers.POST("", h.createTraveller)
+		travellers.GET("", h.listTravellers)
+		travellers.GET("/:traveller_id", h.getTraveller)
+		travellers.PUT("/:traveller_id", h.updateTraveller)
+		travellers.DELETE("/:traveller_id", h.deleteTraveller)
+	}
+
+	// ── Exchange rates ───────────────────────────────────────────────────────
+	rates := router.Group("/exchange-rates", requireAuth, requirePermission(models.PermissionManageRates))
+	{
+		rates.POST("", h.saveExchangeRates)
+		rates.POST("/import", h.importExchangeRates)
+	}
+
+	// ── Audit log ────────────────────────────────────────────────────────────
+	audit := router.Group("/audit", requireAuth, requirePermission(models.PermissionReadAudit))
+	{
+		audit.GET("", h.searchAudit)
+	}
+
 	return router, nil
-}
\ No newline at end of file
+}
+
+// withTimeout returns middleware that attaches a deadline to the request
+// context. Handlers pass that context to the service layer, so a timeout or a
+// client disconnect cancels any in-flight query.
+func withTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		reqCtx, cancel := context.WithTimeout(ctx.Request.Context(), timeout)
+		defer cancel()
+
+		ctx.Request = ctx.Request.WithContext(reqCtx)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// getTripHistory handles GET /trips/:id/history.
+// Returns one page of the changes made to the trip and its bookings, newest
+// first. Accepts the shared pagination query params.
+func (h *handler) getTripHistory(ctx *gin.Context) {
+	tripID := ctx.Param("id")
+
+	page, ok := bindPage(ctx)
+	if !ok {
+		return
+	}
+
+	entries, err := h.svc.GetTripHistory(ctx.Request.Context(), currentActor(ctx), tripID, page)
+	if err != nil {
+		respondError(ctx, "trip not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// searchAudit handles GET /audit.
+// Accepts optional query params: entity_type, entity_id, trip_id, actor_id,
+// action, from and to (YYYY-MM-DD), plus the shared pagination params.
+func (h *handler) searchAudit(ctx *gin.Context) {
+	var params models.AuditSearchParams
+
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		respondBindError(ctx, "query parameters", err)
+		return
+	}
+
+	page, ok := bindPage(ctx)
+	if !ok {
+		return
+	}
+
+	entries, err := h.svc.SearchAudit(ctx.Request.Context(), params, page)
+	if err != nil {
+		respondError(ctx, "", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// actorKey is the gin context key under which the authenticated caller is stored.
+const actorKey = "actor"
+
+// uuidPattern matches the canonical textual form of a UUID.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// tokenClaims are the JWT claims the API understands. Role defaults to
+// traveller when absent.
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Role models.Role `json:"role"`
+}
+
+// authenticate returns middleware that requires an HS256-signed JWT bearer
+// token verified with `secret`. The token's subject becomes the caller's user
+// ID and its role is resolved to permissions through `roles`; requests
+// without a valid token, or with an unknown role, are rejected with 401.
+func authenticate(secret []byte, roles RolePermissions) gin.HandlerFunc {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
+
+	keyFunc := func(*jwt.Token) (interface{}, error) {
+		return secret, nil
+	}
+
+	return func(ctx *gin.Context) {
+		header := ctx.GetHeader("Authorization")
+
+		raw, found := strings.CutPrefix(header, "Bearer ")
+		if !found || raw == "" {
+			abortWithError(ctx, http.StatusUnauthorized, models.ErrorCodeUnauthorized, "missing bearer token")
+			return
+		}
+
+		var claims tokenClaims
+		if _, err := parser.ParseWithClaims(raw, &claims, keyFunc); err != nil {
+			abortWithError(ctx, http.StatusUnauthorized, models.ErrorCodeUnauthorized, "invalid bearer token: "+err.Error())
+			return
+		}
+
+		// user_id columns are UUIDs, so anything else can never own a row.
+		if !uuidPattern.MatchString(claims.Subject) {
+			abortWithError(ctx, http.StatusUnauthorized, models.ErrorCodeUnauthorized, "invalid bearer token: subject must be a user UUID")
+			return
+		}
+
+		if claims.Role == "" {
+			claims.Role = models.RoleTraveller
+		}
+
+		permissions, known := roles[claims.Role]
+		if !known {
+			abortWithError(ctx, http.StatusUnauthorized, models.ErrorCodeUnauthorized, fmt.Sprintf("invalid bearer token: unknown role %q", claims.Role))
+			return
+		}
+
+		ctx.Set(actorKey, models.Actor{
+			UserID:      claims.Subject,
+			Role:        claims.Role,
+			Permissions: permissions,
+		})
+		ctx.Next()
+	}
+}
+
+// currentActor returns the caller stored by authenticate.
+// It must only be used on routes behind that middleware.
+func currentActor(ctx *gin.Context) models.Actor {
+	return ctx.MustGet(actorKey).(models.Actor)
+}
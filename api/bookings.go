@@ -1,65 +1,155 @@
 package api
 
 import (
+	"context"
 	"errors"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
-	"gorm.io/gorm"
 )
 
 // bookItem handles POST /trips/:id/bookings.
 // Creates a booking (hotel, flight, or activity) under the given trip.
+// Accepts an optional currency query param to add converted prices.
 func (h *handler) bookItem(ctx *gin.Context) {
+	currency, ok := bindCurrency(ctx)
+	if !ok {
+		return
+	}
+
 	tripID := ctx.Param("id")
 
 	var req models.CreateBookingRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "invalid request body: " + err.Error(),
-		})
+		respondBindError(ctx, "request body", err)
 		return
 	}
 
-	booking, err := h.svc.BookItem(tripID, req)
+	booking, err := h.svc.BookItem(ctx.Request.Context(), currentActor(ctx), tripID, req)
 	if err != nil {
-		// Surface 404 when the trip or the referenced item is not found.
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, models.ErrorResponse{
-				Error: err.Error(),
-			})
-			return
-		}
-
-		ctx.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
-			Error: err.Error(),
-		})
+		// The message says whether the trip or the booked item is missing.
+		respondError(ctx, "", err)
 		return
 	}
 
+	h.convertWrittenPrices(ctx, currency, bookingPrices(booking))
+
 	ctx.JSON(http.StatusCreated, booking)
 }
 
 // getTripBookings handles GET /trips/:id/bookings.
-// Returns all bookings associated with the given trip.
+// Returns one page of the bookings associated with the given trip. Accepts
+// an optional currency query param to add converted prices.
 func (h *handler) getTripBookings(ctx *gin.Context) {
+	currency, ok := bindCurrency(ctx)
+	if !ok {
+		return
+	}
+
 	tripID := ctx.Param("id")
 
-	bookings, err := h.svc.GetTripBookings(tripID)
+	page, ok := bindPage(ctx)
+	if !ok {
+		return
+	}
+
+	bookings, err := h.svc.GetTripBookings(ctx.Request.Context(), currentActor(ctx), tripID, page)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, models.ErrorResponse{
-				Error: "trip not found",
-			})
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error: err.Error(),
-		})
+		respondError(ctx, "trip not found", err)
+		return
+	}
+
+	if !h.convertPrices(ctx, currency, bookingPrices(pointers(bookings.Items)...)) {
 		return
 	}
 
 	ctx.JSON(http.StatusOK, bookings)
-}
\ No newline at end of file
+}
+
+// getBooking handles GET /bookings/:id.
+// Accepts an optional currency query param to add converted prices. Without
+// one, sets an ETag and answers 304 when If-None-Match already names it.
+func (h *handler) getBooking(ctx *gin.Context) {
+	currency, ok := bindCurrency(ctx)
+	if !ok {
+		return
+	}
+
+	id := ctx.Param("id")
+
+	booking, err := h.svc.GetBooking(ctx.Request.Context(), currentActor(ctx), id)
+	if err != nil {
+		respondError(ctx, "booking not found", err)
+		return
+	}
+
+	// Converted prices follow the daily exchange rate rather than the
+	// booking's version, so only the plain representation gets an ETag.
+	if currency == "" && notModified(ctx, booking.Version) {
+		return
+	}
+
+	if !h.convertPrices(ctx, currency, bookingPrices(booking)) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, booking)
+}
+
+// confirmBooking handles POST /bookings/:id/confirm.
+// Accepts an optional JSON body with a free-text reason and an optional
+// currency query param to add converted prices.
+func (h *handler) confirmBooking(ctx *gin.Context) {
+	h.transitionBooking(ctx, h.svc.ConfirmBooking)
+}
+
+// cancelBooking handles POST /bookings/:id/cancel.
+// Accepts an optional JSON body with a free-text reason and an optional
+// currency query param to add converted prices.
+func (h *handler) cancelBooking(ctx *gin.Context) {
+	h.transitionBooking(ctx, h.svc.CancelBooking)
+}
+
+// getBookingTransitions handles GET /bookings/:id/transitions.
+// Returns the status history of the booking, oldest first.
+func (h *handler) getBookingTransitions(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	transitions, err := h.svc.GetBookingTransitions(ctx.Request.Context(), currentActor(ctx), id)
+	if err != nil {
+		respondError(ctx, "booking not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transitions)
+}
+
+// transitionBooking is the shared body of the confirm and cancel handlers.
+// An illegal transition is reported as 409 Conflict.
+func (h *handler) transitionBooking(ctx *gin.Context, transition func(ctx context.Context, actor models.Actor, id, reason string) (*models.Booking, error)) {
+	currency, ok := bindCurrency(ctx)
+	if !ok {
+		return
+	}
+
+	id := ctx.Param("id")
+
+	// The body is optional, so an empty one (io.EOF) is not an error.
+	var req models.BookingTransitionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondBindError(ctx, "request body", err)
+		return
+	}
+
+	booking, err := transition(ctx.Request.Context(), currentActor(ctx), id, req.Reason)
+	if err != nil {
+		respondError(ctx, "booking not found", err)
+		return
+	}
+
+	h.convertWrittenPrices(ctx, currency, bookingPrices(booking))
+
+	ctx.JSON(http.StatusOK, booking)
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+// bindCurrency reads the optional ?currency= display currency. It returns ""
+// when absent. On an invalid code it writes a 400 response and returns false.
+func bindCurrency(ctx *gin.Context) (string, bool) {
+	currency := strings.ToUpper(ctx.Query("currency"))
+
+	if currency != "" && !models.ValidCurrency(currency) {
+		abortWithError(ctx, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid currency: want an ISO-4217 code such as EUR")
+		return "", false
+	}
+
+	return currency, true
+}
+
+// convertPrices adds the display-currency equivalent to every price when the
+// client asked for one. On failure it writes the error response and returns
+// false; a missing rate is reported as 422.
+func (h *handler) convertPrices(ctx *gin.Context, currency string, prices []*models.Money) bool {
+	if currency == "" {
+		return true
+	}
+
+	if err := h.svc.ConvertPrices(ctx.Request.Context(), currency, prices...); err != nil {
+		respondError(ctx, "", err)
+		return false
+	}
+
+	return true
+}
+
+// convertWrittenPrices is convertPrices for the response to a write that
+// has already been committed. A failed conversion must not turn that
+// response into an error, which a client would retry and the idempotency
+// layer would replay, so the prices are left unconverted and it is logged.
+func (h *handler) convertWrittenPrices(ctx *gin.Context, currency string, prices []*models.Money) {
+	if currency == "" {
+		return
+	}
+
+	if err := h.svc.ConvertPrices(ctx.Request.Context(), currency, prices...); err != nil {
+		log.Warn().Err(err).Str("currency", currency).Msg("converting prices of a write response failed")
+	}
+}
+
+// The helpers below collect the prices of a response so convertPrices can
+// fill them in place.
+
+func hotelPrices(hotels ...*models.Hotel) []*models.Money {
+	prices := make([]*models.Money, 0, len(hotels))
+	for _, hotel := range hotels {
+		prices = append(prices, &hotel.PricePerNight)
+	}
+
+	return prices
+}
+
+func roomTypePrices(roomTypes ...*models.RoomType) []*models.Money {
+	prices := make([]*models.Money, 0, len(roomTypes))
+	for _, roomType := range roomTypes {
+		prices = append(prices, &roomType.PricePerNight)
+	}
+
+	return prices
+}
+
+func flightPrices(flights ...*models.Flight) []*models.Money {
+	prices := make([]*models.Money, 0, len(flights))
+	for _, flight := range flights {
+		prices = append(prices, &flight.Price)
+	}
+
+	return prices
+}
+
+func activityPrices(activities ...*models.Activity) []*models.Money {
+	prices := make([]*models.Money, 0, len(activities))
+	for _, activity := range activities {
+		prices = append(prices, &activity.Price)
+	}
+
+	return prices
+}
+
+func bookingPrices(bookings ...*models.Booking) []*models.Money {
+	prices := make([]*models.Money, 0, len(bookings))
+	for _, booking := range bookings {
+		prices = append(prices, &booking.TotalPrice)
+
+		if booking.PriceBreakdown != nil {
+			prices = append(prices, &booking.PriceBreakdown.UnitPrice, &booking.PriceBreakdown.Total)
+		}
+	}
+
+	return prices
+}
+
+// pointers returns a pointer to every element of items.
+func pointers[T any](items []T) []*T {
+	ptrs := make([]*T, len(items))
+	for i := range items {
+		ptrs[i] = &items[i]
+	}
+
+	return ptrs
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/services"
+	"gorm.io/gorm"
+)
+
+// abortWithError ends the request with an error envelope.
+func abortWithError(ctx *gin.Context, status int, code models.ErrorCode, message string, fields ...models.FieldError) {
+	ctx.AbortWithStatusJSON(status, models.ErrorResponse{
+		Error:  message,
+		Code:   code,
+		Fields: fields,
+	})
+}
+
+// respondError ends the request with the error envelope matching a service
+// failure. Domain errors carry their own status, code and fields. A missing
+// record is reported as notFound, e.g. "trip not found", or with the error's
+// own message when notFound is empty. Anything else is a 500.
+func respondError(ctx *gin.Context, notFound string, err error) {
+	var domainErr *services.Error
+
+	switch {
+	case errors.As(err, &domainErr):
+		abortWithError(ctx, domainErr.Status, domainErr.Code, err.Error(), domainErr.Fields...)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if notFound == "" {
+			notFound = err.Error()
+		}
+
+		abortWithError(ctx, http.StatusNotFound, models.ErrorCodeNotFound, notFound)
+	case errors.Is(err, services.ErrInvalidPage):
+		abortWithError(ctx, http.StatusBadRequest, models.ErrorCodeInvalidPage, err.Error())
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		abortWithError(ctx, http.StatusConflict, models.ErrorCodeConflict, err.Error())
+	default:
+		abortWithError(ctx, http.StatusInternalServerError, models.ErrorCodeInternal, err.Error())
+	}
+}
+
+// respondBindError ends the request with a 400 for a body or query string
+// that could not be bound. source names what was bound, e.g. "request body".
+// Failed binding rules and mistyped JSON values are listed per field.
+func respondBindError(ctx *gin.Context, source string, err error) {
+	var (
+		fields    []models.FieldError
+		invalid   validator.ValidationErrors
+		typeError *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &invalid):
+		for _, fieldErr := range invalid {
+			fields = append(fields, models.FieldError{
+				Field:   fieldPath(fieldErr.Namespace()),
+				Message: ruleMessage(fieldErr),
+			})
+		}
+	case errors.As(err, &typeError):
+		fields = append(fields, models.FieldError{
+			Field:   typeError.Field,
+			Message: fmt.Sprintf("must be a %s, got a %s", typeError.Type, typeError.Value),
+		})
+	}
+
+	abortWithError(ctx, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid "+source+": "+err.Error(), fields...)
+}
+
+// fieldPath turns a validator namespace such as "CreateTripRequest.budget.amount"
+// into the field's path in the request, "budget.amount".
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+
+	return namespace
+}
+
+// ruleMessage describes the binding rule a field broke.
+func ruleMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "uuid":
+		return "must be a UUID"
+	case "gte":
+		return "must be at least " + fieldErr.Param()
+	case "lte":
+		return "must be at most " + fieldErr.Param()
+	case "min":
+		if fieldErr.Kind() == reflect.String {
+			return "must be at least " + fieldErr.Param() + " characters long"
+		}
+
+		return "must be at least " + fieldErr.Param()
+	case "max":
+		if fieldErr.Kind() == reflect.String {
+			return "must be at most " + fieldErr.Param() + " characters long"
+		}
+
+		return "must be at most " + fieldErr.Param()
+	case "oneof":
+		return "must be one of " + fieldErr.Param()
+	default:
+		return fmt.Sprintf("failed the %q rule", fieldErr.Tag())
+	}
+}
+
+// useRequestFieldNames makes binding errors name fields by their JSON or
+// query parameter name instead of their Go name.
+func useRequestFieldNames() {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, key := range []string{"json", "form"} {
+			name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+
+			if name == "-" {
+				return ""
+			}
+
+			if name != "" {
+				return name
+			}
+		}
+
+		return field.Name
+	})
+}
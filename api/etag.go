@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/services"
+)
+
+// etag renders a record version as a strong entity tag.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// notModified sets the ETag of the record about to be returned and, when the
+// request's If-None-Match already names it, answers 304 Not Modified. The
+// handler must not write a body when it returns true.
+func notModified(ctx *gin.Context, version int) bool {
+	tag := etag(version)
+	ctx.Header("ETag", tag)
+
+	// If-None-Match uses the weak comparison: W/"3" matches "3".
+	for _, candidate := range strings.Split(ctx.GetHeader("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+
+		if candidate == "*" || candidate == tag {
+			ctx.Status(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// bindIfMatch reads the If-Match precondition that PUT and DELETE of
+// versioned records require, and returns the version it names, or
+// services.AnyVersion for "*". It answers 428 when the header is missing,
+// 400 when it lists several tags and 412 when it cannot match any version
+// (a weak or non-numeric tag); ok is false in all of these cases.
+func bindIfMatch(ctx *gin.Context) (int, bool) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+
+	if header == "" {
+		abortWithError(ctx, http.StatusPreconditionRequired, models.ErrorCodePreconditionRequired, "If-Match header required: send the ETag of the version being changed")
+		return 0, false
+	}
+
+	if header == "*" {
+		return services.AnyVersion, true
+	}
+
+	if strings.Contains(header, ",") {
+		abortWithError(ctx, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "If-Match must carry a single entity tag")
+		return 0, false
+	}
+
+	// If-Match uses the strong comparison, so a weak tag never matches.
+	unquoted, quoted := strings.CutPrefix(header, `"`)
+	unquoted, closed := strings.CutSuffix(unquoted, `"`)
+
+	version, err := strconv.Atoi(unquoted)
+	if !quoted || !closed || err != nil || version <= 0 {
+		abortWithError(ctx, http.StatusPreconditionFailed, models.ErrorCodeVersionMismatch, "If-Match "+header+" does not match the current version")
+		return 0, false
+	}
+
+	return version, true
+}
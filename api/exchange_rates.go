@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// maxRatesCSVBytes bounds the size of an uploaded exchange-rate CSV.
+const maxRatesCSVBytes = 1 << 20
+
+// ratesCSVHeader is the header row every imported CSV must start with.
+var ratesCSVHeader = []string{"base", "quote", "rate", "effective_date"}
+
+// saveExchangeRates handles POST /exchange-rates.
+// Expects a JSON body with a list of rates; existing rates for the same pair
+// and effective date are replaced.
+func (h *handler) saveExchangeRates(ctx *gin.Context) {
+	var req models.SaveExchangeRatesRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondBindError(ctx, "request body", err)
+		return
+	}
+
+	h.storeExchangeRates(ctx, req.Rates)
+}
+
+// importExchangeRates handles POST /exchange-rates/import.
+// Expects a CSV body with the header base,quote,rate,effective_date and
+// effective dates as YYYY-MM-DD.
+func (h *handler) importExchangeRates(ctx *gin.Context) {
+	rates, err := parseRatesCSV(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxRatesCSVBytes))
+	if err != nil {
+		abortWithError(ctx, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "invalid csv: "+err.Error())
+		return
+	}
+
+	h.storeExchangeRates(ctx, rates)
+}
+
+// storeExchangeRates is the shared tail of the JSON and CSV handlers.
+func (h *handler) storeExchangeRates(ctx *gin.Context, rates []models.ExchangeRate) {
+	saved, err := h.svc.SaveExchangeRates(ctx.Request.Context(), rates)
+	if err != nil {
+		respondError(ctx, "", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, saved)
+}
+
+// parseRatesCSV reads exchange rates from CSV, reporting errors by line.
+func parseRatesCSV(body io.Reader) ([]models.ExchangeRate, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = len(ratesCSVHeader)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("body is empty")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(strings.Join(header, ","), strings.Join(ratesCSVHeader, ",")) {
+		return nil, fmt.Errorf("header must be %q", strings.Join(ratesCSVHeader, ","))
+	}
+
+	var rates []models.ExchangeRate
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		rate, err := models.ParseRate(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		effective, err := time.Parse(time.DateOnly, record[3])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: effective_date must be YYYY-MM-DD", line)
+		}
+
+		rates = append(rates, models.ExchangeRate{
+			Base:          strings.ToUpper(record[0]),
+			Quote:         strings.ToUpper(record[1]),
+			Rate:          rate,
+			EffectiveDate: effective,
+		})
+	}
+
+	return rates, nil
+}
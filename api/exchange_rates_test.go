@@ -0,0 +1,62 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRatesCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "rates",
+			body: "base,quote,rate,effective_date\nUSD,EUR,0.85,2024-01-01\ngbp,usd,1.27,2024-01-02\n",
+			want: []string{"USD/EUR 0.85 2024-01-01", "GBP/USD 1.27 2024-01-02"},
+		},
+		{
+			name: "header in any case and spaces after commas",
+			body: "Base, Quote, Rate, Effective_Date\nUSD, EUR, 0.85, 2024-01-01",
+			want: []string{"USD/EUR 0.85 2024-01-01"},
+		},
+		{
+			name: "header only",
+			body: "base,quote,rate,effective_date\n",
+			want: nil,
+		},
+		{name: "empty body", body: "", wantErr: "body is empty"},
+		{name: "wrong header", body: "from,to,rate,date\nUSD,EUR,0.85,2024-01-01", wantErr: "header must be"},
+		{name: "missing column", body: "base,quote,rate,effective_date\nUSD,EUR,0.85", wantErr: "wrong number of fields"},
+		{name: "bad rate", body: "base,quote,rate,effective_date\nUSD,EUR,0.85,2024-01-01\nUSD,GBP,abc,2024-01-01", wantErr: "line 3"},
+		{name: "too many rate digits", body: "base,quote,rate,effective_date\nUSD,EUR,0.123456789,2024-01-01", wantErr: "line 2"},
+		{name: "bad date", body: "base,quote,rate,effective_date\nUSD,EUR,0.85,01/02/2024", wantErr: "line 2: effective_date must be YYYY-MM-DD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, err := parseRatesCSV(strings.NewReader(tt.body))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseRatesCSV error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parseRatesCSV error: %v", err)
+			}
+
+			var got []string
+			for _, rate := range rates {
+				got = append(got, rate.Base+"/"+rate.Quote+" "+rate.Rate.String()+" "+rate.EffectiveDate.Format("2006-01-02"))
+			}
+
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("parseRatesCSV = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
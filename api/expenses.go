@@ -0,0 +1,72 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// recordExpense handles POST /trips/:id/expenses.
+// Expects a JSON body matching models.CreateExpenseRequest.
+func (h *handler) recordExpense(ctx *gin.Context) {
+	var req models.CreateExpenseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondBindError(ctx, "request body", err)
+		return
+	}
+
+	expense, err := h.svc.RecordExpense(ctx.Request.Context(), currentActor(ctx), ctx.Param("id"), req)
+	if err != nil {
+		respondError(ctx, "trip or booking not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, expense)
+}
+
+// listExpenses handles GET /trips/:id/expenses.
+// Accepts the shared pagination query params: limit, cursor, sort.
+func (h *handler) listExpenses(ctx *gin.Context) {
+	page, ok := bindPage(ctx)
+	if !ok {
+		return
+	}
+
+	expenses, err := h.svc.ListExpenses(ctx.Request.Context(), currentActor(ctx), ctx.Param("id"), page)
+	if err != nil {
+		respondError(ctx, "trip not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, expenses)
+}
+
+// deleteExpense handles DELETE /trips/:id/expenses/:expense_id.
+func (h *handler) deleteExpense(ctx *gin.Context) {
+	if err := h.svc.DeleteExpense(ctx.Request.Context(), currentActor(ctx), ctx.Param("id"), ctx.Param("expense_id")); err != nil {
+		respondError(ctx, "expense not found", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// getTripBalances handles GET /trips/:id/balances.
+// Nets what each member paid against their shares of the trip's expenses
+// and lists the transfers that settle up. Accepts an optional currency query
+// param; the default is the budget's.
+func (h *handler) getTripBalances(ctx *gin.Context) {
+	currency, ok := bindCurrency(ctx)
+	if !ok {
+		return
+	}
+
+	balances, err := h.svc.GetTripBalances(ctx.Request.Context(), currentActor(ctx), ctx.Param("id"), currency)
+	if err != nil {
+		respondError(ctx, "trip not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, balances)
+}
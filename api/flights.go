@@ -1,12 +1,10 @@
 package api
 
 import (
-	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
-	"gorm.io/gorm"
 )
 
 // createFlight handles POST /flights.
@@ -15,17 +13,13 @@ func (h *handler) createFlight(ctx *gin.Context) {
 	var flight models.Flight
 
 	if err := ctx.ShouldBindJSON(&flight); err != nil {
-		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "invalid request body: " + err.Error(),
-		})
+		respondBindError(ctx, "request body", err)
 		return
 	}
 
-	created, err := h.svc.CreateFlight(flight)
+	created, err := h.svc.CreateFlight(ctx.Request.Context(), currentActor(ctx), flight)
 	if err != nil {
-		ctx.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
-			Error: err.Error(),
-		})
+		respondError(ctx, "", err)
 		return
 	}
 
@@ -33,21 +27,22 @@ func (h *handler) createFlight(ctx *gin.Context) {
 }
 
 // getFlight handles GET /flights/:id.
+// Accepts an optional currency query param to add converted prices.
 func (h *handler) getFlight(ctx *gin.Context) {
+	currency, ok := bindCurrency(ctx)
+	if !ok {
+		return
+	}
+
 	id := ctx.Param("id")
 
-	flight, err := h.svc.GetFlight(id)
+	flight, err := h.svc.GetFlight(ctx.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, models.ErrorResponse{
-				Error: "flight not found",
-			})
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error: err.Error(),
-		})
+		respondError(ctx, "flight not found", err)
+		return
+	}
+
+	if !h.convertPrices(ctx, currency, flightPrices(flight)) {
 		return
 	}
 
@@ -55,16 +50,29 @@ func (h *handler) getFlight(ctx *gin.Context) {
 }
 
 // listFlights handles GET /flights.
-// Accepts optional query params: origin, destination (partial, case-insensitive).
+// Accepts optional query params: origin, destination (partial, case-insensitive),
+// currency for converted prices, plus the shared pagination params.
 func (h *handler) listFlights(ctx *gin.Context) {
+	currency, ok := bindCurrency(ctx)
+	if !ok {
+		return
+	}
+
 	origin := ctx.Query("origin")
 	destination := ctx.Query("destination")
 
-	flights, err := h.svc.ListFlights(origin, destination)
+	page, ok := bindPage(ctx)
+	if !ok {
+		return
+	}
+
+	flights, err := h.svc.ListFlights(ctx.Request.Context(), origin, destination, page)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error: err.Error(),
-		})
+		respondError(ctx, "", err)
+		return
+	}
+
+	if !h.convertPrices(ctx, currency, flightPrices(pointers(flights.Items)...)) {
 		return
 	}
 
@@ -1,12 +1,10 @@
 package api
 
 import (
-	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
-	"gorm.io/gorm"
 )
 
 // createHotel handles POST /hotels.
@@ -15,17 +13,13 @@ func (h *handler) createHotel(ctx *gin.Context) {
 	var hotel models.Hotel
 
 	if err := ctx.ShouldBindJSON(&hotel); err != nil {
-		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "invalid request body: " + err.Error(),
-		})
+		respondBindError(ctx, "request body", err)
 		return
 	}
 
-	created, err := h.svc.CreateHotel(hotel)
+	created, err := h.svc.CreateHotel(ctx.Request.Context(), currentActor(ctx), hotel)
 	if err != nil {
-		ctx.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
-			Error: err.Error(),
-		})
+		respondError(ctx, "", err)
 		return
 	}
 
@@ -33,21 +27,22 @@ func (h *handler) createHotel(ctx *gin.Context) {
 }
 
 // getHotel handles GET /hotels/:id.
+// Accepts an optional currency query param to add converted prices.
 func (h *handler) getHotel(ctx *gin.Context) {
+	currency, ok := bindCurrency(ctx)
+	if !ok {
+		return
+	}
+
 	id := ctx.Param("id")
 
-	hotel, err := h.svc.GetHotel(id)
+	hotel, err := h.svc.GetHotel(ctx.Request.Context(), id)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, models.ErrorResponse{
-				Error: "hotel not found",
-			})
-			return
-		}
+		respondError(ctx, "hotel not found", err)
+		return
+	}
 
-		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error: err.Error(),
-		})
+	if !h.convertPrices(ctx, currency, hotelPrices(hotel)) {
 		return
 	}
 
@@ -55,17 +50,87 @@ func (h *handler) getHotel(ctx *gin.Context) {
 }
 
 // listHotels handles GET /hotels.
-// Accepts optional query param: location (partial, case-insensitive match).
+// Accepts optional query params: location (partial, case-insensitive match),
+// check_in and check_out (YYYY-MM-DD) to only list hotels free for those
+// nights, currency for converted prices, plus the shared pagination params.
 func (h *handler) listHotels(ctx *gin.Context) {
-	location := ctx.Query("location")
+	currency, ok := bindCurrency(ctx)
+	if !ok {
+		return
+	}
+
+	var params models.HotelSearchParams
+
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		respondBindError(ctx, "query parameters", err)
+		return
+	}
 
-	hotels, err := h.svc.ListHotels(location)
+	page, ok := bindPage(ctx)
+	if !ok {
+		return
+	}
+
+	hotels, err := h.svc.ListHotels(ctx.Request.Context(), params, page)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error: err.Error(),
-		})
+		respondError(ctx, "", err)
+		return
+	}
+
+	if !h.convertPrices(ctx, currency, hotelPrices(pointers(hotels.Items)...)) {
 		return
 	}
 
 	ctx.JSON(http.StatusOK, hotels)
-}
\ No newline at end of file
+}
+
+// createRoomType handles POST /hotels/:id/room-types.
+// Expects a JSON body matching models.RoomType (without id/hotel_id/timestamps).
+func (h *handler) createRoomType(ctx *gin.Context) {
+	hotelID := ctx.Param("id")
+
+	var roomType models.RoomType
+	if err := ctx.ShouldBindJSON(&roomType); err != nil {
+		respondBindError(ctx, "request body", err)
+		return
+	}
+
+	created, err := h.svc.CreateRoomType(ctx.Request.Context(), currentActor(ctx), hotelID, roomType)
+	if err != nil {
+		respondError(ctx, "hotel not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, created)
+}
+
+// listRoomTypes handles GET /hotels/:id/room-types.
+// Accepts optional query params check_in and check_out (YYYY-MM-DD) to report
+// how many rooms of each type are free for those nights, and currency to add
+// converted prices.
+func (h *handler) listRoomTypes(ctx *gin.Context) {
+	currency, ok := bindCurrency(ctx)
+	if !ok {
+		return
+	}
+
+	hotelID := ctx.Param("id")
+
+	var params models.RoomAvailabilityParams
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		respondBindError(ctx, "query parameters", err)
+		return
+	}
+
+	roomTypes, err := h.svc.ListRoomTypes(ctx.Request.Context(), hotelID, params)
+	if err != nil {
+		respondError(ctx, "hotel not found", err)
+		return
+	}
+
+	if !h.convertPrices(ctx, currency, roomTypePrices(pointers(roomTypes)...)) {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, roomTypes)
+}
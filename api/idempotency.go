@@ -0,0 +1,149 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// idempotencyKeyHeader carries the client-chosen key of a retryable request.
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	// idempotentReplayHeader marks a response replayed from a stored key.
+	idempotentReplayHeader = "Idempotent-Replayed"
+
+	// maxIdempotencyKeyLength matches the key column.
+	maxIdempotencyKeyLength = 255
+
+	// maxIdempotentBodyBytes bounds the request body read for fingerprinting.
+	maxIdempotentBodyBytes = 1 << 20
+)
+
+// idempotent is middleware that makes a write safe to retry. Requests
+// without an Idempotency-Key header pass straight through. The first request
+// with a key is processed and its response stored; a retry with the same
+// key and payload gets that response replayed, while the same key with a
+// different payload is rejected with 422. Responses with a 5xx status are
+// not stored, so the retry runs again. It must run after authenticate, since
+// keys belong to the caller.
+func (h *handler) idempotent(ctx *gin.Context) {
+	key := ctx.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		ctx.Next()
+		return
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		abortWithError(ctx, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Idempotency-Key must be at most 255 characters")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxIdempotentBodyBytes))
+	if err != nil {
+		abortWithError(ctx, http.StatusRequestEntityTooLarge, models.ErrorCodeRequestTooLarge, "request body too large: "+err.Error())
+		return
+	}
+
+	// Hand the handler an unread copy of the body.
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	actor := currentActor(ctx)
+
+	stored, err := h.svc.BeginIdempotentRequest(ctx.Request.Context(), actor, key, requestFingerprint(ctx.Request, body))
+	if err != nil {
+		respondError(ctx, "", err)
+		return
+	}
+
+	if stored != nil {
+		ctx.Header(idempotentReplayHeader, "true")
+		ctx.Data(*stored.StatusCode, stored.ContentType, stored.ResponseBody)
+		ctx.Abort()
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: ctx.Writer}
+	ctx.Writer = recorder
+
+	// Store or release the key even if the request's own deadline has
+	// passed, and even if the handler panics.
+	saveCtx := context.WithoutCancel(ctx.Request.Context())
+	finished := false
+
+	defer func() {
+		if finished {
+			return
+		}
+
+		if err := h.svc.AbandonIdempotentRequest(saveCtx, actor, key); err != nil {
+			log.Error().Err(err).Str("idempotency_key", key).Msg("releasing idempotency key failed")
+		}
+	}()
+
+	ctx.Next()
+
+	status := ctx.Writer.Status()
+	if status >= http.StatusInternalServerError {
+		return
+	}
+
+	if err := h.svc.FinishIdempotentRequest(saveCtx, actor, key, status, ctx.Writer.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+		// The response has already been sent; the key simply will not
+		// replay it and is released instead.
+		log.Error().Err(err).Str("idempotency_key", key).Msg("storing idempotent response failed")
+		return
+	}
+
+	finished = true
+}
+
+// requestFingerprint identifies a request by method, path and payload. JSON
+// payloads are compared by value, so key order and whitespace do not matter.
+func requestFingerprint(req *http.Request, body []byte) string {
+	payload := body
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err == nil {
+		// Maps marshal with sorted keys, which makes the encoding canonical.
+		if canonical, err := json.Marshal(value); err == nil {
+			payload = canonical
+		}
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.URL.Path + "\n"))
+	hash.Write(payload)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder passes a response through to the client while keeping a
+// copy of its body.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write implements io.Writer.
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+// WriteString implements io.StringWriter.
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/services"
+)
+
+func TestRequestFingerprint(t *testing.T) {
+	fingerprint := func(method, path, body string) string {
+		return requestFingerprint(httptest.NewRequest(method, path, nil), []byte(body))
+	}
+
+	base := fingerprint(http.MethodPost, "/trips", `{"title":"Rome","budget":{"amount":"10.00","currency":"EUR"}}`)
+
+	tests := []struct {
+		name           string
+		method, path   string
+		body           string
+		wantSameAsBase bool
+	}{
+		{name: "same request", method: http.MethodPost, path: "/trips", body: `{"title":"Rome","budget":{"amount":"10.00","currency":"EUR"}}`, wantSameAsBase: true},
+		{name: "keys reordered", method: http.MethodPost, path: "/trips", body: `{"budget":{"currency":"EUR","amount":"10.00"},"title":"Rome"}`, wantSameAsBase: true},
+		{name: "whitespace", method: http.MethodPost, path: "/trips", body: "{\n  \"title\": \"Rome\",\n  \"budget\": {\"amount\": \"10.00\", \"currency\": \"EUR\"}\n}", wantSameAsBase: true},
+		{name: "query string ignored", method: http.MethodPost, path: "/trips?x=1", body: `{"title":"Rome","budget":{"amount":"10.00","currency":"EUR"}}`, wantSameAsBase: true},
+		{name: "different value", method: http.MethodPost, path: "/trips", body: `{"title":"Paris","budget":{"amount":"10.00","currency":"EUR"}}`},
+		{name: "different path", method: http.MethodPost, path: "/trips/1/bookings", body: `{"title":"Rome","budget":{"amount":"10.00","currency":"EUR"}}`},
+		{name: "different method", method: http.MethodPut, path: "/trips", body: `{"title":"Rome","budget":{"amount":"10.00","currency":"EUR"}}`},
+		{name: "extra field", method: http.MethodPost, path: "/trips", body: `{"title":"Rome","budget":{"amount":"10.00","currency":"EUR"},"notes":""}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fingerprint(tt.method, tt.path, tt.body); (got == base) != tt.wantSameAsBase {
+				t.Errorf("fingerprint equal to base = %t, want %t", got == base, tt.wantSameAsBase)
+			}
+		})
+	}
+
+	if fingerprint(http.MethodPost, "/rates", "a,b\n1,2") == fingerprint(http.MethodPost, "/rates", "a,b\n1,3") {
+		t.Error("different non-JSON bodies have the same fingerprint")
+	}
+
+	if fingerprint(http.MethodPost, "/x", `{"n":1}`) == fingerprint(http.MethodPost, "/x", `{"n":10}`) {
+		t.Error("different numbers have the same fingerprint")
+	}
+}
+
+// fakeIdempotency keeps idempotency keys in memory the way the service does
+// in the database. The rest of services.Planner is left nil.
+type fakeIdempotency struct {
+	services.Planner
+	keys map[string]*models.IdempotencyKey
+}
+
+func (f *fakeIdempotency) BeginIdempotentRequest(_ context.Context, _ models.Actor, key, fingerprint string) (*models.IdempotencyKey, error) {
+	record, ok := f.keys[key]
+	if !ok {
+		f.keys[key] = &models.IdempotencyKey{Key: key, Fingerprint: fingerprint}
+		return nil, nil
+	}
+
+	if record.Fingerprint != fingerprint {
+		return nil, services.ErrIdempotencyKeyReused
+	}
+
+	if record.StatusCode == nil {
+		return nil, services.ErrIdempotencyKeyInProgress
+	}
+
+	return record, nil
+}
+
+func (f *fakeIdempotency) FinishIdempotentRequest(_ context.Context, _ models.Actor, key string, statusCode int, contentType string, body []byte) error {
+	record := f.keys[key]
+	record.StatusCode, record.ContentType, record.ResponseBody = &statusCode, contentType, body
+
+	return nil
+}
+
+func (f *fakeIdempotency) AbandonIdempotentRequest(_ context.Context, _ models.Actor, key string) error {
+	delete(f.keys, key)
+	return nil
+}
+
+func TestIdempotentMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type request struct {
+		key, body  string
+		wantStatus int
+		wantReplay bool
+		wantCalls  int
+	}
+
+	tests := []struct {
+		name     string
+		status   int
+		requests []request
+	}{
+		{
+			name:   "no key always runs",
+			status: http.StatusCreated,
+			requests: []request{
+				{body: `{"a":1}`, wantStatus: http.StatusCreated, wantCalls: 1},
+				{body: `{"a":1}`, wantStatus: http.StatusCreated, wantCalls: 2},
+			},
+		},
+		{
+			name:   "retry replays",
+			status: http.StatusCreated,
+			requests: []request{
+				{key: "k", body: `{"a":1,"b":2}`, wantStatus: http.StatusCreated, wantCalls: 1},
+				{key: "k", body: `{"b":2, "a":1}`, wantStatus: http.StatusCreated, wantReplay: true, wantCalls: 1},
+				{key: "other", body: `{"a":1,"b":2}`, wantStatus: http.StatusCreated, wantCalls: 2},
+			},
+		},
+		{
+			name:   "client errors are replayed too",
+			status: http.StatusUnprocessableEntity,
+			requests: []request{
+				{key: "k", body: `{}`, wantStatus: http.StatusUnprocessableEntity, wantCalls: 1},
+				{key: "k", body: `{}`, wantStatus: http.StatusUnprocessableEntity, wantReplay: true, wantCalls: 1},
+			},
+		},
+		{
+			name:   "reused key with another payload",
+			status: http.StatusCreated,
+			requests: []request{
+				{key: "k", body: `{"a":1}`, wantStatus: http.StatusCreated, wantCalls: 1},
+				{key: "k", body: `{"a":2}`, wantStatus: http.StatusUnprocessableEntity, wantCalls: 1},
+			},
+		},
+		{
+			name:   "server errors are not stored",
+			status: http.StatusInternalServerError,
+			requests: []request{
+				{key: "k", body: `{}`, wantStatus: http.StatusInternalServerError, wantCalls: 1},
+				{key: "k", body: `{}`, wantStatus: http.StatusInternalServerError, wantCalls: 2},
+			},
+		},
+		{
+			name:   "key too long",
+			status: http.StatusCreated,
+			requests: []request{
+				{key: strings.Repeat("k", maxIdempotencyKeyLength+1), body: `{}`, wantStatus: http.StatusBadRequest},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &handler{svc: &fakeIdempotency{keys: map[string]*models.IdempotencyKey{}}}
+			calls := 0
+
+			router := gin.New()
+			router.POST("/things",
+				func(ctx *gin.Context) { ctx.Set(actorKey, models.Actor{UserID: "user"}) },
+				h.idempotent,
+				func(ctx *gin.Context) {
+					calls++
+					ctx.JSON(tt.status, gin.H{"call": calls})
+				},
+			)
+
+			var first string
+
+			for i, req := range tt.requests {
+				httpReq := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(req.body))
+				if req.key != "" {
+					httpReq.Header.Set(idempotencyKeyHeader, req.key)
+				}
+
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, httpReq)
+
+				if rec.Code != req.wantStatus {
+					t.Errorf("request %d: status = %d, want %d", i, rec.Code, req.wantStatus)
+				}
+
+				if replayed := rec.Header().Get(idempotentReplayHeader) == "true"; replayed != req.wantReplay {
+					t.Errorf("request %d: replayed = %t, want %t", i, replayed, req.wantReplay)
+				}
+
+				if calls != req.wantCalls {
+					t.Errorf("request %d: handler calls = %d, want %d", i, calls, req.wantCalls)
+				}
+
+				if i == 0 {
+					first = rec.Body.String()
+				} else if req.wantReplay && rec.Body.String() != first {
+					t.Errorf("request %d: replayed body = %q, want %q", i, rec.Body.String(), first)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// bindPage reads the shared limit, cursor and sort query params. On invalid
+// input it writes a 400 response and returns false.
+func bindPage(ctx *gin.Context) (models.PageParams, bool) {
+	var page models.PageParams
+
+	if err := ctx.ShouldBindQuery(&page); err != nil {
+		respondBindError(ctx, "pagination parameters", err)
+		return page, false
+	}
+
+	return page, true
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// RolePermissions maps each known role to the permissions it grants.
+// A role missing from the map is rejected at authentication.
+type RolePermissions map[models.Role][]models.Permission
+
+// DefaultRolePermissions returns the built-in role definitions used when no
+// ROLE_PERMISSIONS override is configured.
+func DefaultRolePermissions() RolePermissions {
+	return RolePermissions{
+		models.RoleTraveller: {},
+		models.RoleAgent:     {models.PermissionClientTrips},
+		models.RoleSupplier:  {models.PermissionCatalogueWrite},
+		models.RoleAdmin: {
+			models.PermissionCatalogueWrite,
+			models.PermissionAllTrips,
+			models.PermissionManageAgents,
+			models.PermissionManageRates,
+			models.PermissionReadAudit,
+		},
+	}
+}
+
+// ParseRolePermissions reads role definitions in the form
+// "role=perm|perm;role=perm;role=". A role with nothing after "=" is valid
+// and grants no permissions; a permission that is not one of the
+// models.Permission constants is an error, so a typo cannot go unnoticed.
+// models.RoleSystem is reserved for the audit log and cannot be defined.
+func ParseRolePermissions(spec string) (RolePermissions, error) {
+	roles := RolePermissions{}
+
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, perms, found := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("api: invalid role definition %q, want role=perm|perm", entry)
+		}
+
+		if models.Role(name) == models.RoleSystem {
+			return nil, fmt.Errorf("api: role %q is reserved for background jobs", name)
+		}
+
+		permissions := []models.Permission{}
+		for _, perm := range strings.Split(perms, "|") {
+			if perm = strings.TrimSpace(perm); perm == "" {
+				continue
+			}
+
+			if !models.Permission(perm).Valid() {
+				return nil, fmt.Errorf("api: unknown permission %q for role %q", perm, name)
+			}
+
+			permissions = append(permissions, models.Permission(perm))
+		}
+
+		roles[models.Role(name)] = permissions
+	}
+
+	if len(roles) == 0 {
+		return nil, fmt.Errorf("api: role definitions must not be empty")
+	}
+
+	return roles, nil
+}
+
+// requirePermission returns middleware that rejects callers whose role does
+// not grant `permission` with 403. It must run after authenticate.
+func requirePermission(permission models.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !currentActor(ctx).Can(permission) {
+			abortWithError(ctx, http.StatusForbidden, models.ErrorCodeForbidden, fmt.Sprintf("permission %q required", permission))
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+func TestParseRolePermissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    RolePermissions
+		wantErr bool
+	}{
+		{
+			name: "single role",
+			spec: "agent=trips.clients",
+			want: RolePermissions{"agent": {models.PermissionClientTrips}},
+		},
+		{
+			name: "several roles and permissions",
+			spec: "admin=catalogue.write|audit.read;supplier=catalogue.write",
+			want: RolePermissions{
+				"admin":    {models.PermissionCatalogueWrite, models.PermissionReadAudit},
+				"supplier": {models.PermissionCatalogueWrite},
+			},
+		},
+		{
+			name: "role without permissions",
+			spec: "traveller=",
+			want: RolePermissions{"traveller": {}},
+		},
+		{
+			name: "whitespace and empty entries",
+			spec: " ; agent = trips.clients | ; traveller= ;",
+			want: RolePermissions{"agent": {models.PermissionClientTrips}, "traveller": {}},
+		},
+		{name: "unknown permission", spec: "agent=trips.client", wantErr: true},
+		{name: "unknown permission after valid one", spec: "admin=audit.read|rates.write", wantErr: true},
+		{name: "reserved system role", spec: "system=audit.read", wantErr: true},
+		{name: "reserved system role without permissions", spec: "traveller=;system=", wantErr: true},
+		{name: "missing equals sign", spec: "agent", wantErr: true},
+		{name: "missing role name", spec: "=audit.read", wantErr: true},
+		{name: "empty spec", spec: "", wantErr: true},
+		{name: "only separators", spec: " ; ; ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRolePermissions(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseRolePermissions(%q) = %v, want error", tt.spec, got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseRolePermissions(%q) error: %v", tt.spec, err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRolePermissions(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultRolePermissionsAreKnown(t *testing.T) {
+	for role, permissions := range DefaultRolePermissions() {
+		for _, permission := range permissions {
+			if !permission.Valid() {
+				t.Errorf("role %q grants unknown permission %q", role, permission)
+			}
+		}
+	}
+}
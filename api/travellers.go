@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// createTraveller handles POST /users/:id/travellers.
+// Expects a JSON body matching models.CreateTravellerRequest.
+func (h *handler) createTraveller(ctx *gin.Context) {
+	userID := ctx.Param("id")
+
+	var req models.CreateTravellerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondBindError(ctx, "request body", err)
+		return
+	}
+
+	traveller, err := h.svc.CreateTraveller(ctx.Request.Context(), currentActor(ctx), userID, req)
+	if err != nil {
+		respondError(ctx, "user not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, traveller)
+}
+
+// listTravellers handles GET /users/:id/travellers.
+// Accepts the shared pagination query params: limit, cursor, sort.
+func (h *handler) listTravellers(ctx *gin.Context) {
+	page, ok := bindPage(ctx)
+	if !ok {
+		return
+	}
+
+	travellers, err := h.svc.ListTravellers(ctx.Request.Context(), currentActor(ctx), ctx.Param("id"), page)
+	if err != nil {
+		respondError(ctx, "user not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, travellers)
+}
+
+// getTraveller handles GET /users/:id/travellers/:traveller_id.
+func (h *handler) getTraveller(ctx *gin.Context) {
+	traveller, err := h.svc.GetTraveller(ctx.Request.Context(), currentActor(ctx), ctx.Param("id"), ctx.Param("traveller_id"))
+	if err != nil {
+		respondError(ctx, "traveller not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, traveller)
+}
+
+// updateTraveller handles PUT /users/:id/travellers/:traveller_id.
+// Accepts a partial JSON body; only provided fields are updated.
+func (h *handler) updateTraveller(ctx *gin.Context) {
+	var req models.UpdateTravellerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondBindError(ctx, "request body", err)
+		return
+	}
+
+	updated, err := h.svc.UpdateTraveller(ctx.Request.Context(), currentActor(ctx), ctx.Param("id"), ctx.Param("traveller_id"), req)
+	if err != nil {
+		respondError(ctx, "traveller not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, updated)
+}
+
+// deleteTraveller handles DELETE /users/:id/travellers/:traveller_id.
+// A traveller named on a pending or confirmed booking is reported as 409.
+func (h *handler) deleteTraveller(ctx *gin.Context) {
+	if err := h.svc.DeleteTraveller(ctx.Request.Context(), currentActor(ctx), ctx.Param("id"), ctx.Param("traveller_id")); err != nil {
+		respondError(ctx, "traveller not found", err)
+		return
+	}
+
+	// 204 No Content — successful deletion with no body.
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// listTripMembers handles GET /trips/:id/members.
+func (h *handler) listTripMembers(ctx *gin.Context) {
+	members, err := h.svc.ListTripMembers(ctx.Request.Context(), currentActor(ctx), ctx.Param("id"))
+	if err != nil {
+		respondError(ctx, "trip not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, members)
+}
+
+// removeTripMember handles DELETE /trips/:id/members/:user_id.
+// Members may remove themselves; removing anyone else takes the owner role.
+func (h *handler) removeTripMember(ctx *gin.Context) {
+	if err := h.svc.RemoveTripMember(ctx.Request.Context(), currentActor(ctx), ctx.Param("id"), ctx.Param("user_id")); err != nil {
+		respondError(ctx, "member not found", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// inviteTripMember handles POST /trips/:id/members/invitations.
+// Expects a JSON body matching models.CreateInvitationRequest.
+func (h *handler) inviteTripMember(ctx *gin.Context) {
+	var req models.CreateInvitationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondBindError(ctx, "request body", err)
+		return
+	}
+
+	invitation, err := h.svc.InviteTripMember(ctx.Request.Context(), currentActor(ctx), ctx.Param("id"), req)
+	if err != nil {
+		respondError(ctx, "trip not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, invitation)
+}
+
+// listTripInvitations handles GET /trips/:id/members/invitations.
+func (h *handler) listTripInvitations(ctx *gin.Context) {
+	invitations, err := h.svc.ListTripInvitations(ctx.Request.Context(), currentActor(ctx), ctx.Param("id"))
+	if err != nil {
+		respondError(ctx, "trip not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, invitations)
+}
+
+// revokeTripInvitation handles DELETE /trips/:id/members/invitations/:invitation_id.
+// The invitation is kept with status revoked and returned.
+func (h *handler) revokeTripInvitation(ctx *gin.Context) {
+	invitation, err := h.svc.RevokeTripInvitation(ctx.Request.Context(), currentActor(ctx), ctx.Param("id"), ctx.Param("invitation_id"))
+	if err != nil {
+		respondError(ctx, "invitation not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, invitation)
+}
+
+// acceptTripInvitation handles POST /trips/:id/members/invitations/:invitation_id/accept.
+// Only the invitee may accept; the new membership is returned.
+func (h *handler) acceptTripInvitation(ctx *gin.Context) {
+	member, err := h.svc.AcceptTripInvitation(ctx.Request.Context(), currentActor(ctx), ctx.Param("id"), ctx.Param("invitation_id"))
+	if err != nil {
+		respondError(ctx, "invitation not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, member)
+}
+
+// listMyInvitations handles GET /invitations.
+// Returns the caller's pending invitations.
+func (h *handler) listMyInvitations(ctx *gin.Context) {
+	invitations, err := h.svc.ListMyInvitations(ctx.Request.Context(), currentActor(ctx))
+	if err != nil {
+		respondError(ctx, "", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, invitations)
+}
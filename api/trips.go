@@ -1,12 +1,10 @@
 package api
 
 import (
-	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
-	"gorm.io/gorm"
 )
 
 // createTrip handles POST /trips.
@@ -15,53 +13,96 @@ func (h *handler) createTrip(ctx *gin.Context) {
 	var req models.CreateTripRequest
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "invalid request body: " + err.Error(),
-		})
+		respondBindError(ctx, "request body", err)
 		return
 	}
 
-	trip, err := h.svc.CreateTrip(req)
+	trip, err := h.svc.CreateTrip(ctx.Request.Context(), currentActor(ctx), req)
 	if err != nil {
-		ctx.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
-			Error: err.Error(),
-		})
+		respondError(ctx, "", err)
 		return
 	}
 
+	ctx.Header("ETag", etag(trip.Version))
 	ctx.JSON(http.StatusCreated, trip)
 }
 
 // getTrip handles GET /trips/:id.
+// Sets an ETag and answers 304 when If-None-Match already names it.
 func (h *handler) getTrip(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	trip, err := h.svc.GetTrip(id)
+	trip, err := h.svc.GetTrip(ctx.Request.Context(), currentActor(ctx), id)
 	if err != nil {
-		// Distinguish between "not found" and unexpected DB errors.
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, models.ErrorResponse{
-				Error: "trip not found",
-			})
-			return
-		}
+		respondError(ctx, "trip not found", err)
+		return
+	}
 
-		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error: err.Error(),
-		})
+	if notModified(ctx, trip.Version) {
 		return
 	}
 
 	ctx.JSON(http.StatusOK, trip)
 }
 
+// getTripSummary handles GET /trips/:id/summary.
+// Totals the trip's non-cancelled bookings by type against its budget.
+// Accepts an optional currency query param; the default is the budget's.
+func (h *handler) getTripSummary(ctx *gin.Context) {
+	currency, ok := bindCurrency(ctx)
+	if !ok {
+		return
+	}
+
+	id := ctx.Param("id")
+
+	summary, err := h.svc.GetTripSummary(ctx.Request.Context(), currentActor(ctx), id, currency)
+	if err != nil {
+		respondError(ctx, "trip not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, summary)
+}
+
+// getTripItinerary handles GET /trips/:id/itinerary.
+// Lays the trip's active bookings out day by day with the booked flights,
+// hotels and activities resolved, flagging nights without a hotel.
+func (h *handler) getTripItinerary(ctx *gin.Context) {
+	itinerary, err := h.svc.GetTripItinerary(ctx.Request.Context(), currentActor(ctx), ctx.Param("id"))
+	if err != nil {
+		respondError(ctx, "trip not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, itinerary)
+}
+
+// getTripConflicts handles GET /trips/:id/conflicts.
+// Reports overlapping bookings, impossible flight connections, nights
+// without accommodation and bookings outside the trip dates.
+func (h *handler) getTripConflicts(ctx *gin.Context) {
+	conflicts, err := h.svc.GetTripConflicts(ctx.Request.Context(), currentActor(ctx), ctx.Param("id"))
+	if err != nil {
+		respondError(ctx, "trip not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, conflicts)
+}
+
 // listTrips handles GET /trips.
+// Returns one page of the trips the authenticated caller may manage.
+// Accepts the shared pagination query params: limit, cursor, sort.
 func (h *handler) listTrips(ctx *gin.Context) {
-	trips, err := h.svc.ListTrips()
+	page, ok := bindPage(ctx)
+	if !ok {
+		return
+	}
+
+	trips, err := h.svc.ListTrips(ctx.Request.Context(), currentActor(ctx), page)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error: err.Error(),
-		})
+		respondError(ctx, "", err)
 		return
 	}
 
@@ -69,51 +110,44 @@ func (h *handler) listTrips(ctx *gin.Context) {
 }
 
 // updateTrip handles PUT /trips/:id.
-// Accepts a partial JSON body; only provided fields are updated.
+// Accepts a partial JSON body; only provided fields are updated. Requires an
+// If-Match header with the trip's current ETag, or "*".
 func (h *handler) updateTrip(ctx *gin.Context) {
 	id := ctx.Param("id")
 
+	version, ok := bindIfMatch(ctx)
+	if !ok {
+		return
+	}
+
 	var req models.UpdateTripRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "invalid request body: " + err.Error(),
-		})
+		respondBindError(ctx, "request body", err)
 		return
 	}
 
-	updated, err := h.svc.UpdateTrip(id, req)
+	updated, err := h.svc.UpdateTrip(ctx.Request.Context(), currentActor(ctx), id, version, req)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, models.ErrorResponse{
-				Error: "trip not found",
-			})
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error: err.Error(),
-		})
+		respondError(ctx, "trip not found", err)
 		return
 	}
 
+	ctx.Header("ETag", etag(updated.Version))
 	ctx.JSON(http.StatusOK, updated)
 }
 
 // deleteTrip handles DELETE /trips/:id.
+// Requires an If-Match header with the trip's current ETag, or "*".
 func (h *handler) deleteTrip(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	if err := h.svc.DeleteTrip(id); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			ctx.JSON(http.StatusNotFound, models.ErrorResponse{
-				Error: "trip not found",
-			})
-			return
-		}
+	version, ok := bindIfMatch(ctx)
+	if !ok {
+		return
+	}
 
-		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error: err.Error(),
-		})
+	if err := h.svc.DeleteTrip(ctx.Request.Context(), currentActor(ctx), id, version); err != nil {
+		respondError(ctx, "trip not found", err)
 		return
 	}
 
@@ -121,24 +155,40 @@ func (h *handler) deleteTrip(ctx *gin.Context) {
 	ctx.Status(http.StatusNoContent)
 }
 
+// restoreTrip handles POST /trips/:id/restore.
+// Brings back a deleted trip while it is still within the retention window.
+func (h *handler) restoreTrip(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	trip, err := h.svc.RestoreTrip(ctx.Request.Context(), currentActor(ctx), id)
+	if err != nil {
+		respondError(ctx, "deleted trip not found", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, trip)
+}
+
 // searchTrips handles GET /trips/search.
-// Accepts query params: destination, start_date, end_date (YYYY-MM-DD).
+// Accepts query params: destination, start_date, end_date (YYYY-MM-DD),
+// plus the shared pagination params.
 func (h *handler) searchTrips(ctx *gin.Context) {
 	var params models.TripSearchParams
 
 	// ShouldBindQuery populates the struct from query-string values.
 	if err := ctx.ShouldBindQuery(&params); err != nil {
-		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error: "invalid query parameters: " + err.Error(),
-		})
+		respondBindError(ctx, "query parameters", err)
+		return
+	}
+
+	page, ok := bindPage(ctx)
+	if !ok {
 		return
 	}
 
-	trips, err := h.svc.SearchTrips(params)
+	trips, err := h.svc.SearchTrips(ctx.Request.Context(), currentActor(ctx), params, page)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error: err.Error(),
-		})
+		respondError(ctx, "", err)
 		return
 	}
 
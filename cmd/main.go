@@ -1,15 +1,18 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/ardanlabs/conf/v3"
 	"github.com/joho/godotenv"
 	"github.com/namkatcedrickjumtock/travel-planner/api"
+	"github.com/namkatcedrickjumtock/travel-planner/internal/jobs"
 	"github.com/namkatcedrickjumtock/travel-planner/internal/services"
 	"github.com/namkatcedrickjumtock/travel-planner/persistence"
 	"gorm.io/driver/postgres"
@@ -28,6 +31,31 @@ func run() error {
 	var cfg struct {
 		API struct {
 			ListenPort string `conf:"env:LISTEN_PORT,required"`
+			JWTSecret  string `conf:"env:JWT_SECRET,mask,required"`
+			// Roles overrides the built-in role definitions, e.g.
+			// "admin=catalogue.write|trips.all|agents.manage;agent=trips.clients;traveller=".
+			Roles string `conf:"env:ROLE_PERMISSIONS"`
+			// RequestTimeout is the deadline applied to every request.
+			RequestTimeout time.Duration `conf:"env:REQUEST_TIMEOUT,default:15s"`
+		}
+		Service struct {
+			// DeletedRetention is how long a deleted trip stays restorable
+			// before the purge job removes it for good.
+			DeletedRetention time.Duration `conf:"env:DELETED_TRIP_RETENTION,default:720h"`
+			// IdempotencyTTL is how long an Idempotency-Key replays the
+			// response of the request it was first sent with.
+			IdempotencyTTL time.Duration `conf:"env:IDEMPOTENCY_KEY_TTL,default:24h"`
+		}
+		Jobs struct {
+			// TripCompletionInterval is how often ended trips are marked
+			// completed. Zero disables the job.
+			TripCompletionInterval time.Duration `conf:"env:TRIP_COMPLETION_INTERVAL,default:1h"`
+			// TripPurgeInterval is how often expired deleted trips are purged.
+			// Zero disables the job.
+			TripPurgeInterval time.Duration `conf:"env:TRIP_PURGE_INTERVAL,default:24h"`
+			// IdempotencyPurgeInterval is how often expired idempotency keys
+			// are deleted. Zero disables the job.
+			IdempotencyPurgeInterval time.Duration `conf:"env:IDEMPOTENCY_PURGE_INTERVAL,default:1h"`
 		}
 		DB struct {
 			User           string `conf:"env:DB_USER,mask,required"`
@@ -94,12 +122,52 @@ func run() error {
 		return fmt.Errorf("creating repository: %w", err)
 	}
 
-	svc, err := services.NewTravelPlannerService(repo)
+	svc, err := services.NewTravelPlannerService(repo, services.Config{
+		DeletedRetention: cfg.Service.DeletedRetention,
+		IdempotencyTTL:   cfg.Service.IdempotencyTTL,
+	})
 	if err != nil {
 		return fmt.Errorf("creating service: %w", err)
 	}
 
-	listener, err := api.NewAPIListener(svc)
+	// Background jobs stop when run returns.
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	if cfg.Jobs.TripCompletionInterval > 0 {
+		go jobs.Every(jobsCtx, "complete-ended-trips", cfg.Jobs.TripCompletionInterval, func(ctx context.Context) error {
+			_, err := svc.CompleteEndedTrips(ctx)
+			return err
+		})
+	}
+
+	if cfg.Jobs.TripPurgeInterval > 0 {
+		go jobs.Every(jobsCtx, "purge-deleted-trips", cfg.Jobs.TripPurgeInterval, func(ctx context.Context) error {
+			_, err := svc.PurgeDeletedTrips(ctx)
+			return err
+		})
+	}
+
+	if cfg.Jobs.IdempotencyPurgeInterval > 0 {
+		go jobs.Every(jobsCtx, "purge-idempotency-keys", cfg.Jobs.IdempotencyPurgeInterval, func(ctx context.Context) error {
+			_, err := svc.PurgeExpiredIdempotencyKeys(ctx)
+			return err
+		})
+	}
+
+	// Fall back to the built-in role definitions unless overridden.
+	roles := api.DefaultRolePermissions()
+	if cfg.API.Roles != "" {
+		if roles, err = api.ParseRolePermissions(cfg.API.Roles); err != nil {
+			return fmt.Errorf("parsing role permissions: %w", err)
+		}
+	}
+
+	listener, err := api.NewAPIListener(svc, api.Config{
+		JWTSecret:      cfg.API.JWTSecret,
+		Roles:          roles,
+		RequestTimeout: cfg.API.RequestTimeout,
+	})
 	if err != nil {
 		return fmt.Errorf("creating api listener: %w", err)
 	}
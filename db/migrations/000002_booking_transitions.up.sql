@@ -0,0 +1,11 @@
+CREATE TABLE booking_transitions (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    booking_id  UUID        NOT NULL REFERENCES bookings (id) ON DELETE CASCADE,
+    from_status VARCHAR     NOT NULL CHECK (from_status IN ('pending', 'confirmed', 'cancelled')),
+    to_status   VARCHAR     NOT NULL CHECK (to_status IN ('pending', 'confirmed', 'cancelled')),
+    reason      TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Index supports reading the status history of a single booking in order.
+CREATE INDEX idx_booking_transitions_booking_id ON booking_transitions (booking_id, created_at);
@@ -0,0 +1,3 @@
+-- quantity is the number of seats for flight bookings and the number of
+-- participants for activity bookings. Existing rows count as one unit.
+ALTER TABLE bookings ADD COLUMN quantity INT NOT NULL DEFAULT 1 CHECK (quantity > 0);
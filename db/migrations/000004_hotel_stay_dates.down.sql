@@ -0,0 +1,5 @@
+DROP INDEX idx_bookings_hotel_stay;
+ALTER TABLE bookings
+    DROP CONSTRAINT chk_bookings_stay,
+    DROP COLUMN check_out,
+    DROP COLUMN check_in;
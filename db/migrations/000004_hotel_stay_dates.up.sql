@@ -0,0 +1,12 @@
+-- Hotel bookings cover the nights in [check_in, check_out). Both columns are
+-- NULL for flight and activity bookings.
+ALTER TABLE bookings
+    ADD COLUMN check_in  TIMESTAMPTZ,
+    ADD COLUMN check_out TIMESTAMPTZ,
+    ADD CONSTRAINT chk_bookings_stay CHECK (
+        (check_in IS NULL AND check_out IS NULL)
+        OR (type = 'hotel' AND check_out > check_in)
+    );
+
+-- Index supports the "is this hotel free for these nights" overlap lookup.
+CREATE INDEX idx_bookings_hotel_stay ON bookings (reference_id, check_in, check_out) WHERE type = 'hotel';
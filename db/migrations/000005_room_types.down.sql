@@ -0,0 +1,3 @@
+ALTER TABLE bookings DROP COLUMN room_type_id;
+DROP TABLE room_nights;
+DROP TABLE room_types;
@@ -0,0 +1,24 @@
+CREATE TABLE room_types (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    hotel_id        UUID           NOT NULL REFERENCES hotels (id) ON DELETE CASCADE,
+    name            VARCHAR        NOT NULL,
+    capacity        INT            NOT NULL CHECK (capacity > 0),
+    price_per_night NUMERIC(10, 2) NOT NULL CHECK (price_per_night > 0),
+    room_count      INT            NOT NULL CHECK (room_count > 0),
+    created_at      TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    UNIQUE (hotel_id, name)
+);
+
+-- room_nights is the per-night inventory ledger: one row per room type and
+-- night that has at least one reservation. Nights without a row are fully free.
+CREATE TABLE room_nights (
+    room_type_id   UUID NOT NULL REFERENCES room_types (id) ON DELETE CASCADE,
+    night          DATE NOT NULL,
+    rooms_reserved INT  NOT NULL DEFAULT 0 CHECK (rooms_reserved >= 0),
+    PRIMARY KEY (room_type_id, night)
+);
+
+-- Hotel bookings may target a specific room type; quantity is then the
+-- number of rooms.
+ALTER TABLE bookings ADD COLUMN room_type_id UUID REFERENCES room_types (id);
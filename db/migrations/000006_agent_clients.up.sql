@@ -0,0 +1,8 @@
+-- agent_clients lists which users an agent may manage trips for.
+CREATE TABLE agent_clients (
+    agent_id   UUID        NOT NULL,
+    client_id  UUID        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (agent_id, client_id),
+    CHECK (agent_id <> client_id)
+);
@@ -0,0 +1,14 @@
+ALTER TABLE bookings DROP COLUMN total_price_currency;
+ALTER TABLE bookings RENAME COLUMN total_price_amount TO total_price;
+
+ALTER TABLE activities DROP COLUMN price_currency;
+ALTER TABLE activities RENAME COLUMN price_amount TO price;
+
+ALTER TABLE flights DROP COLUMN price_currency;
+ALTER TABLE flights RENAME COLUMN price_amount TO price;
+
+ALTER TABLE room_types DROP COLUMN price_per_night_currency;
+ALTER TABLE room_types RENAME COLUMN price_per_night_amount TO price_per_night;
+
+ALTER TABLE hotels DROP COLUMN price_per_night_currency;
+ALTER TABLE hotels RENAME COLUMN price_per_night_amount TO price_per_night;
@@ -0,0 +1,22 @@
+-- Every price is an amount plus the ISO-4217 currency it is quoted in. The
+-- amount columns are renamed to <name>_amount so they sit next to their
+-- <name>_currency column; rows that predate currencies are taken to be USD.
+ALTER TABLE hotels RENAME COLUMN price_per_night TO price_per_night_amount;
+ALTER TABLE hotels ADD COLUMN price_per_night_currency CHAR(3) NOT NULL DEFAULT 'USD' CHECK (price_per_night_currency ~ '^[A-Z]{3}$');
+ALTER TABLE hotels ALTER COLUMN price_per_night_currency DROP DEFAULT;
+
+ALTER TABLE room_types RENAME COLUMN price_per_night TO price_per_night_amount;
+ALTER TABLE room_types ADD COLUMN price_per_night_currency CHAR(3) NOT NULL DEFAULT 'USD' CHECK (price_per_night_currency ~ '^[A-Z]{3}$');
+ALTER TABLE room_types ALTER COLUMN price_per_night_currency DROP DEFAULT;
+
+ALTER TABLE flights RENAME COLUMN price TO price_amount;
+ALTER TABLE flights ADD COLUMN price_currency CHAR(3) NOT NULL DEFAULT 'USD' CHECK (price_currency ~ '^[A-Z]{3}$');
+ALTER TABLE flights ALTER COLUMN price_currency DROP DEFAULT;
+
+ALTER TABLE activities RENAME COLUMN price TO price_amount;
+ALTER TABLE activities ADD COLUMN price_currency CHAR(3) NOT NULL DEFAULT 'USD' CHECK (price_currency ~ '^[A-Z]{3}$');
+ALTER TABLE activities ALTER COLUMN price_currency DROP DEFAULT;
+
+ALTER TABLE bookings RENAME COLUMN total_price TO total_price_amount;
+ALTER TABLE bookings ADD COLUMN total_price_currency CHAR(3) NOT NULL DEFAULT 'USD' CHECK (total_price_currency ~ '^[A-Z]{3}$');
+ALTER TABLE bookings ALTER COLUMN total_price_currency DROP DEFAULT;
@@ -0,0 +1,11 @@
+-- exchange_rates holds admin-loaded rates: one unit of base is worth rate
+-- units of quote from effective_date until the next row for the same pair.
+CREATE TABLE exchange_rates (
+    base           CHAR(3)        NOT NULL CHECK (base ~ '^[A-Z]{3}$'),
+    quote          CHAR(3)        NOT NULL CHECK (quote ~ '^[A-Z]{3}$'),
+    effective_date DATE           NOT NULL,
+    rate           NUMERIC(18, 8) NOT NULL CHECK (rate > 0),
+    created_at     TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (base, quote, effective_date),
+    CHECK (base <> quote)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE trips
+    DROP CONSTRAINT chk_trips_budget,
+    DROP COLUMN enforce_budget,
+    DROP COLUMN budget_currency,
+    DROP COLUMN budget_amount;
@@ -0,0 +1,7 @@
+-- A trip may carry a budget. Both budget columns are set together or not
+-- at all; enforce_budget makes over-budget bookings fail instead of warn.
+ALTER TABLE trips
+    ADD COLUMN budget_amount   NUMERIC(10, 2) CHECK (budget_amount > 0),
+    ADD COLUMN budget_currency CHAR(3)        CHECK (budget_currency ~ '^[A-Z]{3}$'),
+    ADD COLUMN enforce_budget  BOOLEAN        NOT NULL DEFAULT FALSE,
+    ADD CONSTRAINT chk_trips_budget CHECK ((budget_amount IS NULL) = (budget_currency IS NULL));
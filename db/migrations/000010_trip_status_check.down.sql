@@ -0,0 +1,2 @@
+DROP INDEX idx_trips_status_end_date;
+ALTER TABLE trips DROP CONSTRAINT chk_trips_status;
@@ -0,0 +1,15 @@
+-- Trip status is now a state machine enforced by the service; the column
+-- only accepts its four states. The column used to accept any text, so
+-- existing rows are normalized first: known states written with different
+-- case or padding are tidied up, and anything else falls back to
+-- 'planning', the state every trip starts in.
+UPDATE trips SET status = LOWER(TRIM(status))
+WHERE status <> LOWER(TRIM(status))
+  AND LOWER(TRIM(status)) IN ('planning', 'confirmed', 'completed', 'cancelled');
+
+UPDATE trips SET status = 'planning' WHERE status NOT IN ('planning', 'confirmed', 'completed', 'cancelled');
+
+ALTER TABLE trips ADD CONSTRAINT chk_trips_status CHECK (status IN ('planning', 'confirmed', 'completed', 'cancelled'));
+
+-- Supports the periodic "complete ended trips" sweep.
+CREATE INDEX idx_trips_status_end_date ON trips (status, end_date);
@@ -0,0 +1,9 @@
+ALTER TABLE bookings
+    DROP CONSTRAINT bookings_trip_id_fkey,
+    ADD CONSTRAINT bookings_trip_id_fkey FOREIGN KEY (trip_id) REFERENCES trips (id) ON DELETE CASCADE;
+
+DROP INDEX idx_bookings_deleted_at;
+DROP INDEX idx_trips_deleted_at;
+
+ALTER TABLE bookings DROP COLUMN deleted_at;
+ALTER TABLE trips    DROP COLUMN deleted_at;
@@ -0,0 +1,13 @@
+-- Trips and bookings are soft-deleted: deleted_at is set instead of removing
+-- the row, and rows are only purged once the retention window has passed.
+ALTER TABLE trips    ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE bookings ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_trips_deleted_at    ON trips (deleted_at);
+CREATE INDEX idx_bookings_deleted_at ON bookings (deleted_at);
+
+-- Deleting a trip must never silently take its booking history with it; the
+-- purge job removes bookings explicitly first.
+ALTER TABLE bookings
+    DROP CONSTRAINT bookings_trip_id_fkey,
+    ADD CONSTRAINT bookings_trip_id_fkey FOREIGN KEY (trip_id) REFERENCES trips (id) ON DELETE RESTRICT;
@@ -0,0 +1,21 @@
+-- audit_entries is the append-only change log of trips, bookings, hotels,
+-- room types and flights. It has no foreign keys on purpose: entries must
+-- outlive the rows they describe, including purged trips.
+CREATE TABLE audit_entries (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    actor_id    UUID,
+    actor_role  VARCHAR     NOT NULL,
+    entity_type VARCHAR     NOT NULL CHECK (entity_type IN ('trip', 'booking', 'hotel', 'room_type', 'flight')),
+    entity_id   UUID        NOT NULL,
+    trip_id     UUID,
+    action      VARCHAR     NOT NULL CHECK (action IN ('create', 'update', 'status_change', 'delete', 'restore', 'purge')),
+    changes     JSONB       NOT NULL DEFAULT '{}',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Indexes support a trip's history, one record's history and the admin
+-- query's time-ordered scans.
+CREATE INDEX idx_audit_entries_trip_id    ON audit_entries (trip_id, created_at) WHERE trip_id IS NOT NULL;
+CREATE INDEX idx_audit_entries_entity     ON audit_entries (entity_type, entity_id, created_at);
+CREATE INDEX idx_audit_entries_actor_id   ON audit_entries (actor_id, created_at);
+CREATE INDEX idx_audit_entries_created_at ON audit_entries (created_at);
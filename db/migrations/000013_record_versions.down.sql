@@ -0,0 +1,2 @@
+ALTER TABLE bookings DROP COLUMN version;
+ALTER TABLE trips    DROP COLUMN version;
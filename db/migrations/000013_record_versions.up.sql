@@ -0,0 +1,4 @@
+-- version is bumped on every update of a trip or booking and is exposed as
+-- its ETag, so clients can make conditional requests.
+ALTER TABLE trips    ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE bookings ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,18 @@
+-- idempotency_keys remembers the outcome of requests sent with an
+-- Idempotency-Key header so retries replay it instead of repeating the
+-- write. Keys are scoped to the user that sent them. status_code is NULL
+-- while the first request is still being processed.
+CREATE TABLE idempotency_keys (
+    user_id       UUID         NOT NULL,
+    key           VARCHAR(255) NOT NULL,
+    fingerprint   CHAR(64)     NOT NULL,
+    status_code   INTEGER,
+    content_type  VARCHAR      NOT NULL DEFAULT '',
+    response_body BYTEA,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    expires_at    TIMESTAMPTZ  NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+
+-- Index supports the purge job's scan for expired keys.
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,2 @@
+DROP TABLE booking_travellers;
+DROP TABLE travellers;
@@ -0,0 +1,26 @@
+-- travellers are the people a user books for: themselves, family, or for
+-- agents' clients, whoever the client travels with. loyalty_numbers is a
+-- JSON array of {program, number} objects.
+CREATE TABLE travellers (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id         UUID        NOT NULL,
+    first_name      VARCHAR     NOT NULL,
+    last_name       VARCHAR     NOT NULL,
+    date_of_birth   DATE        NOT NULL,
+    loyalty_numbers JSONB       NOT NULL DEFAULT '[]',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_travellers_user_id ON travellers (user_id, created_at);
+
+-- booking_travellers is the passenger manifest or guest list of a booking.
+-- Travellers on an active booking cannot be deleted; the service checks
+-- that before deleting, and the cascade clears cancelled bookings' lists.
+CREATE TABLE booking_travellers (
+    booking_id   UUID NOT NULL REFERENCES bookings (id) ON DELETE CASCADE,
+    traveller_id UUID NOT NULL REFERENCES travellers (id) ON DELETE CASCADE,
+    PRIMARY KEY (booking_id, traveller_id)
+);
+
+CREATE INDEX idx_booking_travellers_traveller_id ON booking_travellers (traveller_id);
@@ -0,0 +1,2 @@
+DROP TABLE trip_invitations;
+DROP TABLE trip_members;
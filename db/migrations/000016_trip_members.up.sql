@@ -0,0 +1,33 @@
+-- trip_members grants users other than a trip's owner access to it. Roles
+-- nest: an owner can do everything an editor can, an editor everything a
+-- viewer can. trips.user_id remains the trip's primary owner.
+CREATE TABLE trip_members (
+    trip_id    UUID        NOT NULL REFERENCES trips (id) ON DELETE CASCADE,
+    user_id    UUID        NOT NULL,
+    role       VARCHAR     NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (trip_id, user_id)
+);
+
+CREATE INDEX idx_trip_members_user_id ON trip_members (user_id);
+
+-- Every existing trip's primary owner becomes its first member.
+INSERT INTO trip_members (trip_id, user_id, role, created_at, updated_at)
+SELECT id, user_id, 'owner', created_at, created_at FROM trips;
+
+-- trip_invitations offer a user a role on a trip. Accepting one makes the
+-- invitee a member; at most one invitation per user and trip is pending.
+CREATE TABLE trip_invitations (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    trip_id     UUID        NOT NULL REFERENCES trips (id) ON DELETE CASCADE,
+    invitee_id  UUID        NOT NULL,
+    role        VARCHAR     NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    status      VARCHAR     NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'revoked')),
+    invited_by  UUID        NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_trip_invitations_pending ON trip_invitations (trip_id, invitee_id) WHERE status = 'pending';
+CREATE INDEX idx_trip_invitations_invitee_id ON trip_invitations (invitee_id, created_at) WHERE status = 'pending';
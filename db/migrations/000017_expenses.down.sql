@@ -0,0 +1,7 @@
+DELETE FROM audit_entries WHERE entity_type = 'expense';
+ALTER TABLE audit_entries DROP CONSTRAINT audit_entries_entity_type_check;
+ALTER TABLE audit_entries ADD CONSTRAINT audit_entries_entity_type_check
+    CHECK (entity_type IN ('trip', 'booking', 'hotel', 'room_type', 'flight'));
+
+DROP TABLE expense_shares;
+DROP TABLE expenses;
@@ -0,0 +1,37 @@
+-- expenses record who paid for something on a trip and how its cost is
+-- split between the trip's members. An expense either covers a booking,
+-- whose total_price it copies, or is an ad-hoc cost with its own amount.
+CREATE TABLE expenses (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    trip_id         UUID          NOT NULL REFERENCES trips (id) ON DELETE CASCADE,
+    booking_id      UUID          REFERENCES bookings (id) ON DELETE CASCADE,
+    description     VARCHAR       NOT NULL,
+    amount_amount   NUMERIC(10,2) NOT NULL CHECK (amount_amount > 0),
+    amount_currency CHAR(3)       NOT NULL,
+    paid_by         UUID          NOT NULL,
+    split_method    VARCHAR       NOT NULL CHECK (split_method IN ('equal', 'percentage', 'exact')),
+    created_at      TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_expenses_trip_id ON expenses (trip_id, created_at);
+
+-- A booking is paid for once.
+CREATE UNIQUE INDEX idx_expenses_booking_id ON expenses (booking_id) WHERE booking_id IS NOT NULL;
+
+-- expense_shares is each member's part of an expense. The service computes
+-- the amounts when the expense is recorded so that they always add up to it;
+-- percentage is only kept for percentage splits.
+CREATE TABLE expense_shares (
+    expense_id      UUID          NOT NULL REFERENCES expenses (id) ON DELETE CASCADE,
+    user_id         UUID          NOT NULL,
+    percentage      NUMERIC(5,2),
+    amount_amount   NUMERIC(10,2) NOT NULL CHECK (amount_amount >= 0),
+    amount_currency CHAR(3)       NOT NULL,
+    PRIMARY KEY (expense_id, user_id)
+);
+
+-- Expenses are audited alongside their trip.
+ALTER TABLE audit_entries DROP CONSTRAINT audit_entries_entity_type_check;
+ALTER TABLE audit_entries ADD CONSTRAINT audit_entries_entity_type_check
+    CHECK (entity_type IN ('trip', 'booking', 'hotel', 'room_type', 'flight', 'expense'));
@@ -0,0 +1,3 @@
+-- enforce_conflicts makes bookings that overlap another booking or fall
+-- outside the trip fail instead of warn.
+ALTER TABLE trips ADD COLUMN enforce_conflicts BOOLEAN NOT NULL DEFAULT FALSE;
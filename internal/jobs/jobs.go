@@ -0,0 +1,32 @@
+// Package jobs runs periodic background work inside the server process.
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Every runs fn immediately and then once per interval until ctx is
+// cancelled. Each run gets a context bounded by the interval, so a stuck run
+// cannot pile up behind the next one. Errors are logged and do not stop the
+// loop.
+func Every(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		runCtx, cancel := context.WithTimeout(ctx, interval)
+		if err := fn(runCtx); err != nil {
+			log.Error().Err(err).Str("job", name).Msg("background job failed")
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
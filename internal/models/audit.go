@@ -0,0 +1,104 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditEntity names the kind of record an audit entry describes.
+type AuditEntity string
+
+const (
+	AuditEntityTrip     AuditEntity = "trip"
+	AuditEntityBooking  AuditEntity = "booking"
+	AuditEntityHotel    AuditEntity = "hotel"
+	AuditEntityRoomType AuditEntity = "room_type"
+	AuditEntityFlight   AuditEntity = "flight"
+	AuditEntityExpense  AuditEntity = "expense"
+)
+
+// AuditAction names what was done to an audited record.
+type AuditAction string
+
+const (
+	AuditActionCreate       AuditAction = "create"
+	AuditActionUpdate       AuditAction = "update"
+	AuditActionStatusChange AuditAction = "status_change"
+	AuditActionDelete       AuditAction = "delete"
+	AuditActionRestore      AuditAction = "restore"
+	AuditActionPurge        AuditAction = "purge"
+)
+
+// FieldChange is the value of one field before and after a change, each as
+// the field's JSON encoding. Before is omitted for fields a change added,
+// After for fields it removed.
+type FieldChange struct {
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditChanges maps the JSON name of every changed field to its change. It is
+// stored as a JSONB document.
+type AuditChanges map[string]FieldChange
+
+// Scan implements sql.Scanner for JSONB columns.
+func (c *AuditChanges) Scan(src interface{}) error {
+	var raw []byte
+
+	switch v := src.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("models: cannot scan %T into AuditChanges", src)
+	}
+
+	return json.Unmarshal(raw, c)
+}
+
+// Value implements driver.Valuer, writing the changes as a JSON document.
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(raw), nil
+}
+
+// AuditEntry records one change to a trip, booking, expense, hotel, room
+// type or flight: who made it, what it was and which fields it changed.
+// ActorID is nil for changes made by background jobs. TripID is set for trips
+// and their bookings and expenses so a trip's whole history can be read at
+// once. Rows are append-only and outlive the records they describe.
+type AuditEntry struct {
+	ID         string       `json:"id"                gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	ActorID    *string      `json:"actor_id"          gorm:"type:uuid"`
+	ActorRole  Role         `json:"actor_role"        gorm:"type:varchar;not null"`
+	EntityType AuditEntity  `json:"entity_type"       gorm:"type:varchar;not null"`
+	EntityID   string       `json:"entity_id"         gorm:"type:uuid;not null"`
+	TripID     *string      `json:"trip_id,omitempty" gorm:"type:uuid"`
+	Action     AuditAction  `json:"action"            gorm:"type:varchar;not null"`
+	Changes    AuditChanges `json:"changes"           gorm:"type:jsonb;not null"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// AuditSearchParams carries the filters of the admin audit query.
+// Zero-value fields are ignored; From and To bound created_at by day, both
+// inclusive.
+type AuditSearchParams struct {
+	EntityType AuditEntity `form:"entity_type"`
+	EntityID   string      `form:"entity_id" binding:"omitempty,uuid"`
+	TripID     string      `form:"trip_id"   binding:"omitempty,uuid"`
+	ActorID    string      `form:"actor_id"  binding:"omitempty,uuid"`
+	Action     AuditAction `form:"action"`
+	From       time.Time   `form:"from"      time_format:"2006-01-02"`
+	To         time.Time   `form:"to"        time_format:"2006-01-02"`
+}
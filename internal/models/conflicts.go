@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// ConflictKind names a problem with a trip's itinerary.
+type ConflictKind string
+
+const (
+	// ConflictOverlap is two bookings at the same time for the same
+	// travellers: overlapping flights, an activity during a flight, or stays
+	// at two hotels on the same night. A booking naming no travellers counts
+	// as being for everyone.
+	ConflictOverlap ConflictKind = "overlap"
+
+	// ConflictImpossibleConnection is a flight departing from somewhere
+	// other than where the same traveller's previous flight landed.
+	ConflictImpossibleConnection ConflictKind = "impossible_connection"
+
+	// ConflictAccommodationGap is a run of nights with neither a hotel stay
+	// nor an overnight flight.
+	ConflictAccommodationGap ConflictKind = "accommodation_gap"
+
+	// ConflictOutsideTrip is a booking that starts before the trip's first
+	// day or ends after its last.
+	ConflictOutsideTrip ConflictKind = "outside_trip"
+)
+
+// ConflictSeverity says how sure a conflict is to be a mistake.
+type ConflictSeverity string
+
+const (
+	// ConflictSeverityError marks clashes no traveller can make work. Trips
+	// with EnforceConflicts refuse bookings that cause one.
+	ConflictSeverityError ConflictSeverity = "error"
+
+	// ConflictSeverityWarning marks gaps the traveller may have planned to
+	// fill outside the trip, e.g. by train or by staying with friends.
+	ConflictSeverityWarning ConflictSeverity = "warning"
+)
+
+// Conflict is one problem found in a trip's itinerary. BookingIDs lists the
+// bookings involved; From and To give the first and last night of an
+// accommodation gap.
+type Conflict struct {
+	Kind       ConflictKind     `json:"kind"`
+	Severity   ConflictSeverity `json:"severity"`
+	Message    string           `json:"message"`
+	BookingIDs []string         `json:"booking_ids,omitempty"`
+	From       *time.Time       `json:"from,omitempty"`
+	To         *time.Time       `json:"to,omitempty"`
+}
+
+// TripConflicts is the result of checking a trip's active bookings.
+type TripConflicts struct {
+	TripID    string     `json:"trip_id"`
+	Errors    int        `json:"errors"`
+	Warnings  int        `json:"warnings"`
+	Conflicts []Conflict `json:"conflicts"`
+}
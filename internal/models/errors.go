@@ -0,0 +1,43 @@
+package models
+
+// ErrorCode is the machine-readable kind of an error response. Codes are part
+// of the API: clients match on them instead of on error messages, so an
+// existing code must never change meaning.
+type ErrorCode string
+
+const (
+	// Request errors.
+	ErrorCodeInvalidRequest       ErrorCode = "invalid_request"
+	ErrorCodeValidationFailed     ErrorCode = "validation_failed"
+	ErrorCodeInvalidPage          ErrorCode = "invalid_page"
+	ErrorCodeRequestTooLarge      ErrorCode = "request_too_large"
+	ErrorCodeUnauthorized         ErrorCode = "unauthorized"
+	ErrorCodeForbidden            ErrorCode = "forbidden"
+	ErrorCodeNotFound             ErrorCode = "not_found"
+	ErrorCodeConflict             ErrorCode = "conflict"
+	ErrorCodePreconditionRequired ErrorCode = "precondition_required"
+	ErrorCodeVersionMismatch      ErrorCode = "version_mismatch"
+
+	// Idempotency errors.
+	ErrorCodeIdempotencyKeyReused     ErrorCode = "idempotency_key_reused"
+	ErrorCodeIdempotencyKeyInProgress ErrorCode = "idempotency_key_in_progress"
+
+	// Trip and booking rules.
+	ErrorCodeInvalidTripTransition    ErrorCode = "invalid_trip_transition"
+	ErrorCodeInvalidBookingTransition ErrorCode = "invalid_booking_transition"
+	ErrorCodeTripNotBookable          ErrorCode = "trip_not_bookable"
+	ErrorCodeInsufficientInventory    ErrorCode = "insufficient_inventory"
+	ErrorCodeBudgetExceeded           ErrorCode = "budget_exceeded"
+	ErrorCodeItineraryConflict        ErrorCode = "itinerary_conflict"
+	ErrorCodePriceMismatch            ErrorCode = "price_mismatch"
+	ErrorCodeRestoreWindowExpired     ErrorCode = "restore_window_expired"
+	ErrorCodeTravellerInUse           ErrorCode = "traveller_in_use"
+	ErrorCodeAlreadyMember            ErrorCode = "already_member"
+	ErrorCodeInvitationClosed         ErrorCode = "invitation_closed"
+
+	// Currency errors.
+	ErrorCodeCurrencyRequired ErrorCode = "currency_required"
+	ErrorCodeNoExchangeRate   ErrorCode = "no_exchange_rate"
+
+	ErrorCodeInternal ErrorCode = "internal_error"
+)
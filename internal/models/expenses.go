@@ -0,0 +1,106 @@
+package models
+
+import "time"
+
+// SplitMethod is how an expense's cost is divided between members.
+type SplitMethod string
+
+const (
+	// SplitEqual divides the cost evenly; leftover cents go to the first
+	// shares.
+	SplitEqual SplitMethod = "equal"
+
+	// SplitPercentage gives every share a percentage; they must add up to 100.
+	SplitPercentage SplitMethod = "percentage"
+
+	// SplitExact gives every share an amount; they must add up to the cost.
+	SplitExact SplitMethod = "exact"
+)
+
+// Valid reports whether m is one of the defined split methods.
+func (m SplitMethod) Valid() bool {
+	switch m {
+	case SplitEqual, SplitPercentage, SplitExact:
+		return true
+	default:
+		return false
+	}
+}
+
+// Expense records that PaidBy paid Amount for something on a trip and how
+// the cost is split. Expenses that cover a booking copy its TotalPrice and
+// stop counting towards balances once the booking is cancelled.
+type Expense struct {
+	ID          string         `json:"id"                   gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	TripID      string         `json:"trip_id"              gorm:"type:uuid;not null;index"`
+	BookingID   *string        `json:"booking_id,omitempty" gorm:"type:uuid"`
+	Description string         `json:"description"          gorm:"type:varchar;not null"`
+	Amount      Money          `json:"amount"               gorm:"embedded;embeddedPrefix:amount_"`
+	PaidBy      string         `json:"paid_by"              gorm:"type:uuid;not null"`
+	SplitMethod SplitMethod    `json:"split_method"         gorm:"type:varchar;not null"`
+	Shares      []ExpenseShare `json:"shares"               gorm:"foreignKey:ExpenseID"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// ExpenseShare is one member's part of an expense. Amount is in the
+// expense's currency, and the shares of an expense always add up to it.
+type ExpenseShare struct {
+	ExpenseID string `json:"-"       gorm:"type:uuid;primaryKey"`
+	UserID    string `json:"user_id" gorm:"type:uuid;primaryKey"`
+
+	// Percentage is set for percentage splits only, with two fractional
+	// digits, e.g. "33.34".
+	Percentage *Amount `json:"percentage,omitempty" gorm:"type:numeric(5,2)"`
+	Amount     Money   `json:"amount"               gorm:"embedded;embeddedPrefix:amount_"`
+}
+
+// CreateExpenseRequest is the payload for recording an expense on a trip.
+// Set BookingID to record who paid for a booking, whose total price is then
+// the amount; otherwise Amount and Description are required.
+type CreateExpenseRequest struct {
+	BookingID   *string     `json:"booking_id"   binding:"omitempty,uuid"`
+	Description string      `json:"description"`
+	Amount      *Money      `json:"amount"`
+	PaidBy      string      `json:"paid_by"      binding:"required,uuid"`
+	SplitMethod SplitMethod `json:"split_method" binding:"required"`
+
+	// Shares names who the cost is split between. It may be left out of an
+	// equal split to split it between every member of the trip.
+	Shares []ExpenseShareRequest `json:"shares" binding:"omitempty,dive"`
+}
+
+// ExpenseShareRequest is one entry of CreateExpenseRequest.Shares. Percentage
+// is required for percentage splits and Amount for exact ones.
+type ExpenseShareRequest struct {
+	UserID     string  `json:"user_id"    binding:"required,uuid"`
+	Percentage *Amount `json:"percentage"`
+	Amount     *Amount `json:"amount"`
+}
+
+// TripBalances is where a trip's members stand after its expenses, and the
+// transfers that settle up, all in one currency.
+type TripBalances struct {
+	TripID    string          `json:"trip_id"`
+	Currency  string          `json:"currency"`
+	Expenses  int             `json:"expenses"`
+	Balances  []MemberBalance `json:"balances"`
+	Transfers []Transfer      `json:"transfers"`
+	Rates     []ExchangeRate  `json:"rates,omitempty"`
+}
+
+// MemberBalance is what a member paid, what their shares came to and the
+// difference: positive when they are owed money, negative when they owe it.
+type MemberBalance struct {
+	UserID string `json:"user_id"`
+	Paid   Money  `json:"paid"`
+	Owed   Money  `json:"owed"`
+	Net    Money  `json:"net"`
+}
+
+// Transfer is a payment of Amount from one member to another.
+type Transfer struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Amount Money  `json:"amount"`
+}
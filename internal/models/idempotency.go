@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// IdempotencyKey is the stored outcome of a request sent with an
+// Idempotency-Key header. Fingerprint identifies the request the key was
+// first used for; StatusCode stays nil until that request has finished, after
+// which the response is replayed to retries until ExpiresAt.
+type IdempotencyKey struct {
+	UserID       string `gorm:"type:uuid;primaryKey"`
+	Key          string `gorm:"type:varchar(255);primaryKey"`
+	Fingerprint  string `gorm:"type:char(64);not null"`
+	StatusCode   *int   `gorm:"type:integer"`
+	ContentType  string `gorm:"type:varchar;not null"`
+	ResponseBody []byte `gorm:"type:bytea"`
+	CreatedAt    time.Time
+	ExpiresAt    time.Time `gorm:"not null;index"`
+}
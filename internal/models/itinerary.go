@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// ItineraryEvent says what an itinerary item marks.
+type ItineraryEvent string
+
+const (
+	ItineraryEventFlight   ItineraryEvent = "flight"
+	ItineraryEventCheckIn  ItineraryEvent = "check_in"
+	ItineraryEventStay     ItineraryEvent = "stay"
+	ItineraryEventCheckOut ItineraryEvent = "check_out"
+	ItineraryEventActivity ItineraryEvent = "activity"
+)
+
+// Itinerary is a trip's active bookings laid out day by day, from the
+// trip's start date to its end date, with the booked items resolved.
+type Itinerary struct {
+	Trip Trip           `json:"trip"`
+	Days []ItineraryDay `json:"days"`
+
+	// NightsWithoutHotel counts the days whose HotelMissing is set.
+	NightsWithoutHotel int `json:"nights_without_hotel"`
+
+	// Unscheduled holds the bookings that fall outside the trip's dates,
+	// e.g. a flight booked for the day before the trip starts.
+	Unscheduled []ItineraryItem `json:"unscheduled"`
+}
+
+// ItineraryDay is one calendar day of a trip, in UTC.
+type ItineraryDay struct {
+	Date  time.Time       `json:"date"`
+	Items []ItineraryItem `json:"items"`
+
+	// HotelMissing is set when no hotel stay or overnight flight covers the
+	// night that follows the day. The trip's last day has no night.
+	HotelMissing bool `json:"hotel_missing"`
+}
+
+// ItineraryItem is one booking as it shows on a day. Hotel stays appear on
+// every day of the stay: check_in on the first, check_out on the last and
+// stay in between. Starts and Ends are nil for hotel events, which have
+// dates but no times.
+type ItineraryItem struct {
+	Event   ItineraryEvent `json:"event"`
+	Starts  *time.Time     `json:"starts,omitempty"`
+	Ends    *time.Time     `json:"ends,omitempty"`
+	Booking Booking        `json:"booking"`
+
+	// The booked flight, hotel or activity, matching the booking's type,
+	// plus the room type of hotels booked by type. They are nil if the item
+	// has since been removed from the catalogue.
+	Flight   *Flight   `json:"flight,omitempty"`
+	Hotel    *Hotel    `json:"hotel,omitempty"`
+	RoomType *RoomType `json:"room_type,omitempty"`
+	Activity *Activity `json:"activity,omitempty"`
+}
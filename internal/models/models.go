@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // TripStatus represents the lifecycle state of a trip.
 type TripStatus string
@@ -30,17 +34,99 @@ const (
 	BookingStatusCancelled BookingStatus = "cancelled"
 )
 
-// Trip is a top-level travel itinerary owned by a user.
+// Role is a named set of permissions assigned to a user by their token.
+type Role string
+
+const (
+	RoleTraveller Role = "traveller"
+	RoleAgent     Role = "agent"
+	RoleSupplier  Role = "supplier"
+	RoleAdmin     Role = "admin"
+
+	// RoleSystem is recorded in the audit log for changes made by background
+	// jobs. It has no permissions and is not meant to appear in tokens.
+	RoleSystem Role = "system"
+)
+
+// Permission is a single capability granted through a role.
+type Permission string
+
+const (
+	// PermissionCatalogueWrite allows creating and changing hotels, flights
+	// and activities.
+	PermissionCatalogueWrite Permission = "catalogue.write"
+	// PermissionClientTrips allows acting on the trips of assigned clients.
+	PermissionClientTrips Permission = "trips.clients"
+	// PermissionAllTrips allows acting on any user's trips.
+	PermissionAllTrips Permission = "trips.all"
+	// PermissionManageAgents allows assigning clients to agents.
+	PermissionManageAgents Permission = "agents.manage"
+	// PermissionManageRates allows loading exchange rates.
+	PermissionManageRates Permission = "rates.manage"
+	// PermissionReadAudit allows querying the audit log of every record.
+	PermissionReadAudit Permission = "audit.read"
+)
+
+// Valid reports whether p is one of the known permissions.
+func (p Permission) Valid() bool {
+	switch p {
+	case PermissionCatalogueWrite, PermissionClientTrips, PermissionAllTrips,
+		PermissionManageAgents, PermissionManageRates, PermissionReadAudit:
+		return true
+	default:
+		return false
+	}
+}
+
+// Actor identifies the authenticated caller on whose behalf an operation
+// runs, along with the permissions their role grants.
+type Actor struct {
+	UserID      string       `json:"user_id"`
+	Role        Role         `json:"role"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// Can reports whether the actor holds the given permission.
+func (a Actor) Can(permission Permission) bool {
+	for _, p := range a.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AgentClient records that an agent may manage a client's trips.
+type AgentClient struct {
+	AgentID   string    `json:"agent_id"   gorm:"type:uuid;primaryKey"`
+	ClientID  string    `json:"client_id"  gorm:"type:uuid;primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Trip is a top-level travel itinerary owned by a user. Budget is optional;
+// when EnforceBudget is set, bookings that would exceed it are rejected
+// instead of only producing a warning. EnforceConflicts does the same for
+// bookings that clash with the itinerary. Version is bumped on every update
+// and serves as the trip's ETag.
 type Trip struct {
-	ID          string     `json:"id"           gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
-	UserID      string     `json:"user_id"      gorm:"type:uuid;not null;index"`
-	Title       string     `json:"title"        gorm:"type:varchar;not null"`
-	Destination string     `json:"destination"  gorm:"type:varchar;not null"`
-	StartDate   time.Time  `json:"start_date"   gorm:"not null"`
-	EndDate     time.Time  `json:"end_date"     gorm:"not null"`
-	Status      TripStatus `json:"status"       gorm:"type:varchar;not null;default:'planning'"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	ID               string     `json:"id"                gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID           string     `json:"user_id"           gorm:"type:uuid;not null;index"`
+	Title            string     `json:"title"             gorm:"type:varchar;not null"`
+	Destination      string     `json:"destination"       gorm:"type:varchar;not null"`
+	StartDate        time.Time  `json:"start_date"        gorm:"not null"`
+	EndDate          time.Time  `json:"end_date"          gorm:"not null"`
+	Status           TripStatus `json:"status"            gorm:"type:varchar;not null;default:'planning'"`
+	Budget           *Money     `json:"budget,omitempty"  gorm:"embedded;embeddedPrefix:budget_"`
+	EnforceBudget    bool       `json:"enforce_budget"    gorm:"not null;default:false"`
+	EnforceConflicts bool       `json:"enforce_conflicts" gorm:"not null;default:false"`
+	Version          int        `json:"version"           gorm:"not null;default:1"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	// DeletedAt marks a soft-deleted trip. GORM hides such rows from every
+	// query unless it is made Unscoped.
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 // Hotel represents an accommodation option available for booking.
@@ -48,7 +134,7 @@ type Hotel struct {
 	ID            string    `json:"id"             gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
 	Name          string    `json:"name"           gorm:"type:varchar;not null"`
 	Location      string    `json:"location"       gorm:"type:varchar;not null"`
-	PricePerNight float64   `json:"price_per_night" gorm:"type:numeric(10,2);not null"`
+	PricePerNight Money     `json:"price_per_night" gorm:"embedded;embeddedPrefix:price_per_night_"`
 	Rating        float64   `json:"rating"         gorm:"type:numeric(3,2)"`
 	AvailableFrom time.Time `json:"available_from"`
 	AvailableTo   time.Time `json:"available_to"`
@@ -56,6 +142,30 @@ type Hotel struct {
 	UpdatedAt     time.Time `json:"updated_at"`
 }
 
+// RoomType is a category of room sold by a hotel, e.g. "Double" or "Suite".
+// RoomCount is how many physical rooms of this type exist on any night.
+type RoomType struct {
+	ID            string    `json:"id"              gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	HotelID       string    `json:"hotel_id"        gorm:"type:uuid;not null;index"`
+	Name          string    `json:"name"            gorm:"type:varchar;not null"`
+	Capacity      int       `json:"capacity"        gorm:"not null"`
+	PricePerNight Money     `json:"price_per_night" gorm:"embedded;embeddedPrefix:price_per_night_"`
+	RoomCount     int       `json:"room_count"      gorm:"not null"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	// RoomsAvailable is the number of rooms free on every night of a
+	// requested range. Only populated when listing with check_in/check_out.
+	RoomsAvailable *int `json:"rooms_available,omitempty" gorm:"-"`
+}
+
+// RoomNight is one entry of the per-night room inventory ledger.
+type RoomNight struct {
+	RoomTypeID    string    `json:"room_type_id"   gorm:"type:uuid;primaryKey"`
+	Night         time.Time `json:"night"          gorm:"type:date;primaryKey"`
+	RoomsReserved int       `json:"rooms_reserved" gorm:"not null;default:0"`
+}
+
 // Flight represents a flight available for booking.
 type Flight struct {
 	ID             string    `json:"id"               gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
@@ -64,7 +174,7 @@ type Flight struct {
 	Destination    string    `json:"destination"      gorm:"type:varchar(100);not null"`
 	DepartureTime  time.Time `json:"departure_time"   gorm:"not null"`
 	ArrivalTime    time.Time `json:"arrival_time"     gorm:"not null"`
-	Price          float64   `json:"price"            gorm:"type:numeric(10,2);not null"`
+	Price          Money     `json:"price"            gorm:"embedded;embeddedPrefix:price_"`
 	SeatsAvailable int       `json:"seats_available"  gorm:"not null;default:0"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
@@ -76,7 +186,7 @@ type Activity struct {
 	Name          string    `json:"name"           gorm:"type:varchar;not null"`
 	Location      string    `json:"location"       gorm:"type:varchar;not null"`
 	Description   string    `json:"description"    gorm:"type:text"`
-	Price         float64   `json:"price"          gorm:"type:numeric(10,2);not null"`
+	Price         Money     `json:"price"          gorm:"embedded;embeddedPrefix:price_"`
 	DurationHours float64   `json:"duration_hours" gorm:"type:numeric(5,2);not null"`
 	AvailableDate time.Time `json:"available_date"`
 	CreatedAt     time.Time `json:"created_at"`
@@ -85,15 +195,87 @@ type Activity struct {
 
 // Booking links a Trip to a Hotel, Flight, or Activity.
 // ReferenceID points to the ID of the booked item (hotel/flight/activity).
+// Quantity is the number of rooms for hotels, seats for flights and
+// participants for activities. CheckIn/CheckOut are only set for hotel
+// bookings and cover the nights in [CheckIn, CheckOut). RoomTypeID is set
+// when the hotel sells rooms by type. Version is bumped on every status
+// change and serves as the booking's ETag.
 type Booking struct {
 	ID          string        `json:"id"           gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
 	TripID      string        `json:"trip_id"      gorm:"type:uuid;not null;index"`
 	Type        BookingType   `json:"type"         gorm:"type:varchar;not null"`
 	ReferenceID string        `json:"reference_id" gorm:"type:uuid;not null"`
 	Status      BookingStatus `json:"status"       gorm:"type:varchar;not null;default:'pending'"`
-	TotalPrice  float64       `json:"total_price"  gorm:"type:numeric(10,2);not null"`
+	Quantity    int           `json:"quantity"     gorm:"not null;default:1"`
+	CheckIn     *time.Time    `json:"check_in,omitempty"`
+	CheckOut    *time.Time    `json:"check_out,omitempty"`
+	RoomTypeID  *string       `json:"room_type_id,omitempty" gorm:"type:uuid"`
+	TotalPrice  Money         `json:"total_price"  gorm:"embedded;embeddedPrefix:total_price_"`
+	Version     int           `json:"version"      gorm:"not null;default:1"`
 	CreatedAt   time.Time     `json:"created_at"`
 	UpdatedAt   time.Time     `json:"updated_at"`
+
+	// Travellers names who the booking is for: a flight's passengers, a
+	// hotel's guests or an activity's participants.
+	Travellers []Traveller `json:"travellers,omitempty" gorm:"many2many:booking_travellers"`
+
+	// DeletedAt is set when the booking's trip is soft-deleted, to the same
+	// instant as the trip's, so both are restored together.
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// PriceBreakdown explains how TotalPrice was computed. It is only
+	// populated on the response to the request that created the booking.
+	PriceBreakdown *PriceBreakdown `json:"price_breakdown,omitempty" gorm:"-"`
+
+	// Warnings carries non-fatal notices about the booking, such as the trip
+	// going over budget. Like PriceBreakdown it is only set on creation.
+	Warnings []string `json:"warnings,omitempty" gorm:"-"`
+}
+
+// PriceBreakdown shows how a booking's total was derived from catalogue prices.
+type PriceBreakdown struct {
+	Unit      string `json:"unit"`
+	UnitPrice Money  `json:"unit_price"`
+	Units     int    `json:"units"`
+	Total     Money  `json:"total"`
+}
+
+// BookingSpend is the total of a trip's non-cancelled bookings of one type
+// in one currency.
+type BookingSpend struct {
+	Type     BookingType
+	Currency string
+	Amount   Amount
+	Bookings int
+}
+
+// TripSummary totals a trip's non-cancelled bookings in a single currency.
+// Remaining is negative when the trip is over budget. AverageSpendPerDay is
+// Spent spread evenly over the trip's Days. Rates lists the exchange rates
+// used to bring bookings in other currencies into Currency.
+type TripSummary struct {
+	TripID             string                `json:"trip_id"`
+	Currency           string                `json:"currency"`
+	Bookings           int                   `json:"bookings"`
+	Spent              Money                 `json:"spent"`
+	ByType             map[BookingType]Money `json:"by_type"`
+	Budget             *Money                `json:"budget,omitempty"`
+	Remaining          *Money                `json:"remaining,omitempty"`
+	OverBudget         bool                  `json:"over_budget"`
+	Days               int                   `json:"days"`
+	AverageSpendPerDay Money                 `json:"average_spend_per_day"`
+	Rates              []ExchangeRate        `json:"rates,omitempty"`
+}
+
+// BookingTransition records a single status change of a booking, together
+// with the reason given for it. Rows are append-only.
+type BookingTransition struct {
+	ID         string        `json:"id"          gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	BookingID  string        `json:"booking_id"  gorm:"type:uuid;not null;index"`
+	FromStatus BookingStatus `json:"from_status" gorm:"type:varchar;not null"`
+	ToStatus   BookingStatus `json:"to_status"   gorm:"type:varchar;not null"`
+	Reason     string        `json:"reason"      gorm:"type:text;not null"`
+	CreatedAt  time.Time     `json:"created_at"`
 }
 
 // ─────────────────────────────────────────────
@@ -101,39 +283,146 @@ type Booking struct {
 // ─────────────────────────────────────────────
 
 // CreateTripRequest is the payload for creating a new trip.
+// The owner is the authenticated caller unless an agent sets ClientID to
+// create the trip for one of their clients.
 type CreateTripRequest struct {
-	UserID      string    `json:"user_id"     binding:"required"`
-	Title       string    `json:"title"       binding:"required"`
-	Destination string    `json:"destination" binding:"required"`
-	StartDate   time.Time `json:"start_date"  binding:"required"`
-	EndDate     time.Time `json:"end_date"    binding:"required"`
+	ClientID         *string   `json:"client_id"`
+	Title            string    `json:"title"       binding:"required"`
+	Destination      string    `json:"destination" binding:"required"`
+	StartDate        time.Time `json:"start_date"  binding:"required"`
+	EndDate          time.Time `json:"end_date"    binding:"required"`
+	Budget           *Money    `json:"budget"`
+	EnforceBudget    bool      `json:"enforce_budget"`
+	EnforceConflicts bool      `json:"enforce_conflicts"`
 }
 
 // UpdateTripRequest is the payload for updating an existing trip.
 // All fields are optional — only provided fields will be updated.
 type UpdateTripRequest struct {
-	Title       *string     `json:"title"`
-	Destination *string     `json:"destination"`
-	StartDate   *time.Time  `json:"start_date"`
-	EndDate     *time.Time  `json:"end_date"`
-	Status      *TripStatus `json:"status"`
+	Title            *string     `json:"title"`
+	Destination      *string     `json:"destination"`
+	StartDate        *time.Time  `json:"start_date"`
+	EndDate          *time.Time  `json:"end_date"`
+	Status           *TripStatus `json:"status"`
+	Budget           *Money      `json:"budget"`
+	EnforceBudget    *bool       `json:"enforce_budget"`
+	EnforceConflicts *bool       `json:"enforce_conflicts"`
+}
+
+// PageParams carries the pagination contract shared by every list endpoint.
+// Limit defaults to 20. Cursor is the opaque next_cursor of a previous page
+// and is only valid with the same Sort. Sort names a whitelisted field,
+// prefixed with "-" for descending order.
+type PageParams struct {
+	Limit  int    `form:"limit"  binding:"omitempty,gte=1,lte=100"`
+	Cursor string `form:"cursor"`
+	Sort   string `form:"sort"`
+}
+
+// Page is the response envelope of every list endpoint. NextCursor is empty
+// on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // TripSearchParams carries filter criteria for searching trips.
+// UserIDs and MemberID are set by the service from the caller, never from the
+// query string.
 type TripSearchParams struct {
+	UserIDs     []string  `form:"-"`
+	MemberID    string    `form:"-"`
 	Destination string    `form:"destination"`
 	StartDate   time.Time `form:"start_date"  time_format:"2006-01-02"`
 	EndDate     time.Time `form:"end_date"    time_format:"2006-01-02"`
 }
 
+// UpdateActivityRequest is the payload for updating an existing activity.
+// All fields are optional — only provided fields will be updated.
+type UpdateActivityRequest struct {
+	Name          *string    `json:"name"`
+	Location      *string    `json:"location"`
+	Description   *string    `json:"description"`
+	Price         *Money     `json:"price"`
+	DurationHours *float64   `json:"duration_hours"`
+	AvailableDate *time.Time `json:"available_date"`
+}
+
+// HotelSearchParams carries filter criteria for listing hotels.
+// When CheckIn/CheckOut are set only hotels free for every night in
+// [CheckIn, CheckOut) are returned.
+type HotelSearchParams struct {
+	Location string    `form:"location"`
+	CheckIn  time.Time `form:"check_in"  time_format:"2006-01-02"`
+	CheckOut time.Time `form:"check_out" time_format:"2006-01-02"`
+}
+
+// RoomAvailabilityParams optionally restricts a room type listing to the
+// rooms free on every night in [CheckIn, CheckOut).
+type RoomAvailabilityParams struct {
+	CheckIn  time.Time `form:"check_in"  time_format:"2006-01-02"`
+	CheckOut time.Time `form:"check_out" time_format:"2006-01-02"`
+}
+
+// ActivitySearchParams carries filter criteria for listing activities.
+// Zero-value and nil fields are ignored. The price bounds compare amounts
+// only; they are not converted between currencies.
+type ActivitySearchParams struct {
+	Location string    `form:"location"`
+	Date     time.Time `form:"date"      time_format:"2006-01-02"`
+	MinPrice *Amount   `form:"min_price"`
+	MaxPrice *Amount   `form:"max_price"`
+}
+
 // CreateBookingRequest is the payload for booking an item within a trip.
+// Quantity defaults to 1 when omitted. Hotel bookings must also carry
+// CheckIn/CheckOut, from which the number of nights is derived, and a
+// RoomTypeID when the hotel sells rooms by type. TotalPrice is
+// optional: the server computes the price itself and rejects a supplied
+// total that differs in amount or currency.
 type CreateBookingRequest struct {
 	Type        BookingType `json:"type"         binding:"required"`
 	ReferenceID string      `json:"reference_id" binding:"required"`
-	TotalPrice  float64     `json:"total_price"  binding:"required,gt=0"`
+	Quantity    int         `json:"quantity"     binding:"omitempty,gte=1"`
+	CheckIn     *time.Time  `json:"check_in"`
+	CheckOut    *time.Time  `json:"check_out"`
+	RoomTypeID  *string     `json:"room_type_id"`
+	TotalPrice  *Money      `json:"total_price"`
+
+	// TravellerIDs lists the travellers the booking is for. They must belong
+	// to the trip's owner or one of its members.
+	// Flight bookings need one per seat.
+	TravellerIDs []string `json:"traveller_ids" binding:"omitempty,dive,uuid"`
 }
 
-// ErrorResponse is a uniform error envelope returned by all endpoints.
+// BookingTransitionRequest is the optional payload for confirming or
+// cancelling a booking.
+type BookingTransitionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// SaveExchangeRatesRequest is the payload for loading exchange rates.
+type SaveExchangeRatesRequest struct {
+	Rates []ExchangeRate `json:"rates" binding:"required,min=1"`
+}
+
+// AssignClientRequest is the payload for assigning a client to an agent.
+type AssignClientRequest struct {
+	ClientID string `json:"client_id" binding:"required,uuid"`
+}
+
+// ErrorResponse is a uniform error envelope returned by all endpoints. Code
+// is stable and meant for programs; Error is for people and may change.
+// Fields lists the request fields that failed validation, if any.
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error  string       `json:"error"`
+	Code   ErrorCode    `json:"code"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError is one request field that failed validation. Field is the
+// field's JSON or query parameter name.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
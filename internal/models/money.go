@@ -0,0 +1,439 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCurrencyMismatch is returned by Money arithmetic when the operands are
+// in different currencies.
+var ErrCurrencyMismatch = errors.New("models: currency mismatch")
+
+// Amount is an exact decimal amount with two fractional digits, held as an
+// integer number of hundredths so that sums and products never drift. Two
+// digits match the NUMERIC(10,2) price columns.
+//
+// Amounts serialise to JSON as strings ("12.50") and accept either strings
+// or number literals on input; more than two fractional digits is an error
+// rather than a silent rounding.
+type Amount struct {
+	hundredths int64
+}
+
+// NewAmount returns the amount of `hundredths` hundredths, e.g. 1250 → 12.50.
+func NewAmount(hundredths int64) Amount {
+	return Amount{hundredths: hundredths}
+}
+
+// ParseAmount parses a decimal string such as "12", "12.5" or "-0.99".
+func ParseAmount(s string) (Amount, error) {
+	hundredths, err := parseFixed(s, 2)
+	if err != nil {
+		return Amount{}, fmt.Errorf("models: invalid amount %q: %w", s, err)
+	}
+
+	return Amount{hundredths: hundredths}, nil
+}
+
+// parseFixed parses a decimal string into an integer scaled by 10^scale,
+// rejecting inputs with more than `scale` fractional digits.
+func parseFixed(s string, scale int) (int64, error) {
+	digits := s
+	negative := strings.HasPrefix(digits, "-")
+	if negative {
+		digits = digits[1:]
+	}
+
+	whole, frac, _ := strings.Cut(digits, ".")
+	if whole == "" || len(frac) > scale || !isDigits(whole) || !isDigits(frac) {
+		return 0, fmt.Errorf("want a decimal with at most %d fractional digits", scale)
+	}
+
+	unit := int64(math.Pow10(scale))
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > math.MaxInt64/unit-1 {
+		return 0, fmt.Errorf("out of range")
+	}
+
+	var fraction int64
+	if frac != "" {
+		fraction, _ = strconv.ParseInt(frac+strings.Repeat("0", scale-len(frac)), 10, 64)
+	}
+
+	scaled := units*unit + fraction
+	if negative {
+		scaled = -scaled
+	}
+
+	return scaled, nil
+}
+
+// formatFixed renders an integer scaled by 10^scale with exactly `scale`
+// fractional digits.
+func formatFixed(scaled int64, scale int) string {
+	sign := ""
+	if scaled < 0 {
+		sign, scaled = "-", -scaled
+	}
+
+	unit := int64(math.Pow10(scale))
+
+	return fmt.Sprintf("%s%d.%0*d", sign, scaled/unit, scale, scaled%unit)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Hundredths returns the amount as an integer number of hundredths.
+func (a Amount) Hundredths() int64 {
+	return a.hundredths
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of the amount.
+func (a Amount) Sign() int {
+	switch {
+	case a.hundredths < 0:
+		return -1
+	case a.hundredths > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Cmp compares a and b, returning -1, 0 or +1.
+func (a Amount) Cmp(b Amount) int {
+	return Amount{hundredths: a.hundredths - b.hundredths}.Sign()
+}
+
+// Add returns a + b.
+func (a Amount) Add(b Amount) Amount {
+	return Amount{hundredths: a.hundredths + b.hundredths}
+}
+
+// Mul returns a × n.
+func (a Amount) Mul(n int64) Amount {
+	return Amount{hundredths: a.hundredths * n}
+}
+
+// String renders the amount with exactly two fractional digits.
+func (a Amount) String() string {
+	return formatFixed(a.hundredths, 2)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It is also what gin uses to bind
+// an Amount from a query parameter.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	text := string(data)
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseAmount(text)
+	if err != nil {
+		return err
+	}
+
+	*a = parsed
+
+	return nil
+}
+
+// Scan implements sql.Scanner for NUMERIC columns.
+func (a *Amount) Scan(src interface{}) error {
+	var text string
+
+	switch v := src.(type) {
+	case nil:
+		*a = Amount{}
+		return nil
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	case int64:
+		*a = Amount{hundredths: v * 100}
+		return nil
+	case float64:
+		text = strconv.FormatFloat(v, 'f', 2, 64)
+	default:
+		return fmt.Errorf("models: cannot scan %T into Amount", src)
+	}
+
+	parsed, err := ParseAmount(text)
+	if err != nil {
+		return err
+	}
+
+	*a = parsed
+
+	return nil
+}
+
+// Value implements driver.Valuer. The amount is sent as decimal text so the
+// database never sees a binary float.
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Money is an exact amount in an ISO-4217 currency. Models embed it with a
+// column prefix, e.g. `gorm:"embedded;embeddedPrefix:price_"` maps to the
+// price_amount and price_currency columns.
+type Money struct {
+	Amount   Amount `json:"amount"   gorm:"column:amount;type:numeric(10,2);not null"`
+	Currency string `json:"currency" gorm:"column:currency;type:char(3);not null"`
+
+	// Converted is the same value in the display currency a client asked
+	// for with ?currency=. It is never stored.
+	Converted *Conversion `json:"converted,omitempty" gorm:"-"`
+}
+
+// ValidCurrency reports whether code has the shape of an ISO-4217 alphabetic
+// code: three upper-case ASCII letters.
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Validate checks the currency code and that the amount is strictly positive,
+// which every catalogue price and booking total must be.
+func (m Money) Validate() error {
+	if !ValidCurrency(m.Currency) {
+		return fmt.Errorf("models: invalid currency %q: want an ISO-4217 code such as USD", m.Currency)
+	}
+
+	if m.Amount.Sign() <= 0 {
+		return fmt.Errorf("models: amount must be greater than 0, got %s", m.Amount)
+	}
+
+	return nil
+}
+
+// Add returns m + o. It refuses to add amounts in different currencies.
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: cannot add %s to %s", ErrCurrencyMismatch, o.Currency, m.Currency)
+	}
+
+	return Money{Amount: m.Amount.Add(o.Amount), Currency: m.Currency}, nil
+}
+
+// Sub returns m - o. It refuses to subtract amounts in different currencies.
+func (m Money) Sub(o Money) (Money, error) {
+	return m.Add(o.Mul(-1))
+}
+
+// Div returns m / n rounded half away from zero to the nearest hundredth.
+// It returns an error unless n is positive.
+func (m Money) Div(n int64) (Money, error) {
+	if n <= 0 {
+		return Money{}, fmt.Errorf("models: cannot divide %s by %d", m, n)
+	}
+
+	h := m.Amount.hundredths
+	q, r := h/n, h%n
+	if r < 0 {
+		r = -r
+	}
+
+	if 2*r >= n {
+		if h < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+
+	return Money{Amount: Amount{hundredths: q}, Currency: m.Currency}, nil
+}
+
+// Convert expresses m in rate.Quote, rounding half away from zero to the
+// nearest hundredth. The rate's base must be m's currency.
+func (m Money) Convert(rate ExchangeRate) (Money, error) {
+	if m.Currency != rate.Base {
+		return Money{}, fmt.Errorf("%w: cannot convert %s with a %s/%s rate", ErrCurrencyMismatch, m.Currency, rate.Base, rate.Quote)
+	}
+
+	product := new(big.Int).Mul(big.NewInt(m.Amount.hundredths), big.NewInt(rate.Rate.scaled))
+	unit := big.NewInt(rateUnit)
+
+	quotient, remainder := new(big.Int).QuoRem(product, unit, new(big.Int))
+	if remainder.Abs(remainder).Mul(remainder, big.NewInt(2)).Cmp(unit) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(product.Sign())))
+	}
+
+	if !quotient.IsInt64() {
+		return Money{}, fmt.Errorf("models: converting %s to %s overflows", m, rate.Quote)
+	}
+
+	return Money{Amount: Amount{hundredths: quotient.Int64()}, Currency: rate.Quote}, nil
+}
+
+// Mul returns m × n in the same currency.
+func (m Money) Mul(n int64) Money {
+	return Money{Amount: m.Amount.Mul(n), Currency: m.Currency}
+}
+
+// Equal reports whether m and o are the same amount in the same currency.
+func (m Money) Equal(o Money) bool {
+	return m.Currency == o.Currency && m.Amount.Cmp(o.Amount) == 0
+}
+
+// String renders the money as "12.50 EUR".
+func (m Money) String() string {
+	return m.Amount.String() + " " + m.Currency
+}
+
+// rateScale is the number of fractional digits an exchange rate carries,
+// matching the NUMERIC(18,8) rate column.
+const rateScale = 8
+
+// rateUnit is 10^rateScale.
+const rateUnit = 100_000_000
+
+// Rate is an exact, strictly positive exchange rate with up to eight
+// fractional digits. Like Amount it travels as a JSON string.
+type Rate struct {
+	scaled int64
+}
+
+// ParseRate parses a decimal string such as "0.8512".
+func ParseRate(s string) (Rate, error) {
+	scaled, err := parseFixed(s, rateScale)
+	if err != nil {
+		return Rate{}, fmt.Errorf("models: invalid rate %q: %w", s, err)
+	}
+
+	return Rate{scaled: scaled}, nil
+}
+
+// IsPositive reports whether the rate is greater than zero.
+func (r Rate) IsPositive() bool {
+	return r.scaled > 0
+}
+
+// String renders the rate without trailing zeros, e.g. "0.8512" or "1".
+func (r Rate) String() string {
+	return strings.TrimSuffix(strings.TrimRight(formatFixed(r.scaled, rateScale), "0"), ".")
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting strings or numbers.
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	text := string(data)
+	if strings.HasPrefix(text, `"`) {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseRate(text)
+	if err != nil {
+		return err
+	}
+
+	*r = parsed
+
+	return nil
+}
+
+// Scan implements sql.Scanner for NUMERIC columns.
+func (r *Rate) Scan(src interface{}) error {
+	var text string
+
+	switch v := src.(type) {
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	case int64:
+		*r = Rate{scaled: v * rateUnit}
+		return nil
+	case float64:
+		text = strconv.FormatFloat(v, 'f', rateScale, 64)
+	default:
+		return fmt.Errorf("models: cannot scan %T into Rate", src)
+	}
+
+	parsed, err := ParseRate(text)
+	if err != nil {
+		return err
+	}
+
+	*r = parsed
+
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+// ExchangeRate says that one unit of Base is worth Rate units of Quote from
+// EffectiveDate until the next rate for the same pair takes effect.
+type ExchangeRate struct {
+	Base          string    `json:"base"           gorm:"type:char(3);primaryKey"`
+	Quote         string    `json:"quote"          gorm:"type:char(3);primaryKey"`
+	EffectiveDate time.Time `json:"effective_date" gorm:"type:date;primaryKey"`
+	Rate          Rate      `json:"rate"           gorm:"type:numeric(18,8);not null"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// IdentityRate returns the rate of 1 that converts a currency to itself.
+func IdentityRate(currency string, effective time.Time) ExchangeRate {
+	return ExchangeRate{Base: currency, Quote: currency, EffectiveDate: effective, Rate: Rate{scaled: rateUnit}}
+}
+
+// Conversion is a Money value expressed in a display currency, together
+// with the rate used and the date that rate took effect.
+type Conversion struct {
+	Amount        Amount    `json:"amount"`
+	Currency      string    `json:"currency"`
+	Rate          Rate      `json:"rate"`
+	EffectiveDate time.Time `json:"effective_date"`
+}
@@ -0,0 +1,355 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: "0", want: 0},
+		{in: "12", want: 1200},
+		{in: "12.5", want: 1250},
+		{in: "12.50", want: 1250},
+		{in: "0.01", want: 1},
+		{in: "-0.99", want: -99},
+		{in: "-12", want: -1200},
+		{in: "92233720368547757.00", want: 9223372036854775700},
+		{in: "12.505", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: ".5", wantErr: true},
+		{in: "+1", wantErr: true},
+		{in: "1e3", wantErr: true},
+		{in: "1,50", wantErr: true},
+		{in: " 1", wantErr: true},
+		{in: "1.-5", wantErr: true},
+		{in: "92233720368547758", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseAmount(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseAmount(%q) = %s, want error", tt.in, got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseAmount(%q) error: %v", tt.in, err)
+			}
+
+			if got.Hundredths() != tt.want {
+				t.Errorf("ParseAmount(%q) = %d hundredths, want %d", tt.in, got.Hundredths(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAmountString(t *testing.T) {
+	tests := []struct {
+		hundredths int64
+		want       string
+	}{
+		{0, "0.00"},
+		{1, "0.01"},
+		{1250, "12.50"},
+		{-99, "-0.99"},
+		{-1200, "-12.00"},
+	}
+
+	for _, tt := range tests {
+		if got := NewAmount(tt.hundredths).String(); got != tt.want {
+			t.Errorf("NewAmount(%d).String() = %q, want %q", tt.hundredths, got, tt.want)
+		}
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: `"12.50"`, want: 1250},
+		{in: `12.5`, want: 1250},
+		{in: `7`, want: 700},
+		{in: `null`, want: 0},
+		{in: `"12.505"`, wantErr: true},
+		{in: `12.505`, wantErr: true},
+		{in: `"abc"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var got Amount
+
+			err := json.Unmarshal([]byte(tt.in), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Unmarshal(%s) = %s, want error", tt.in, got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unmarshal(%s) error: %v", tt.in, err)
+			}
+
+			if got.Hundredths() != tt.want {
+				t.Errorf("Unmarshal(%s) = %d hundredths, want %d", tt.in, got.Hundredths(), tt.want)
+			}
+		})
+	}
+
+	out, err := json.Marshal(NewAmount(1250))
+	if err != nil || string(out) != `"12.50"` {
+		t.Errorf(`Marshal(12.50) = %s, %v, want "12.50"`, out, err)
+	}
+}
+
+func TestAmountScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    int64
+		wantErr bool
+	}{
+		{name: "nil", src: nil, want: 0},
+		{name: "bytes", src: []byte("12.50"), want: 1250},
+		{name: "string", src: "-0.99", want: -99},
+		{name: "int64", src: int64(12), want: 1200},
+		{name: "float64", src: 12.5, want: 1250},
+		{name: "float64 with binary error", src: 0.1 + 0.2, want: 30},
+		{name: "too many digits", src: "1.234", wantErr: true},
+		{name: "unsupported type", src: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewAmount(1)
+
+			err := got.Scan(tt.src)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Scan(%v) = %s, want error", tt.src, got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Scan(%v) error: %v", tt.src, err)
+			}
+
+			if got.Hundredths() != tt.want {
+				t.Errorf("Scan(%v) = %d hundredths, want %d", tt.src, got.Hundredths(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAmountValueRoundTrip(t *testing.T) {
+	for _, hundredths := range []int64{0, 1, -1, 1250, -99, 123456789} {
+		value, err := NewAmount(hundredths).Value()
+		if err != nil {
+			t.Fatalf("Value(%d) error: %v", hundredths, err)
+		}
+
+		if _, ok := value.(string); !ok {
+			t.Fatalf("Value(%d) = %T, want string", hundredths, value)
+		}
+
+		var got Amount
+		if err := got.Scan(value); err != nil {
+			t.Fatalf("Scan(%v) error: %v", value, err)
+		}
+
+		if got.Hundredths() != hundredths {
+			t.Errorf("round trip of %d hundredths gave %d", hundredths, got.Hundredths())
+		}
+	}
+}
+
+func TestRateRoundTrip(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "1", want: "1"},
+		{in: "0.8512", want: "0.8512"},
+		{in: "1.10000000", want: "1.1"},
+		{in: "0.00000001", want: "0.00000001"},
+		{in: "0.000000001", wantErr: true},
+		{in: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			rate, err := ParseRate(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseRate(%q) = %s, want error", tt.in, rate)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseRate(%q) error: %v", tt.in, err)
+			}
+
+			value, _ := rate.Value()
+
+			var scanned Rate
+			if err := scanned.Scan(value); err != nil {
+				t.Fatalf("Scan(%v) error: %v", value, err)
+			}
+
+			if got := scanned.String(); got != tt.want {
+				t.Errorf("round trip of %q = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyConvertRounding(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		rate   string
+		want   int64
+	}{
+		{name: "exact", amount: 1000, rate: "0.85", want: 850},
+		{name: "half rounds up", amount: 100, rate: "0.125", want: 13},
+		{name: "negative half rounds away from zero", amount: -100, rate: "0.125", want: -13},
+		{name: "below half rounds down", amount: 1, rate: "0.49999999", want: 0},
+		{name: "exactly half a cent", amount: 1, rate: "0.5", want: 1},
+		{name: "large rate", amount: 1999, rate: "151.23456789", want: 302318},
+		{name: "identity", amount: 4242, rate: "1", want: 4242},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := ParseRate(tt.rate)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Money{Amount: NewAmount(tt.amount), Currency: "USD"}.Convert(ExchangeRate{Base: "USD", Quote: "EUR", Rate: rate})
+			if err != nil {
+				t.Fatalf("Convert error: %v", err)
+			}
+
+			if got.Currency != "EUR" || got.Amount.Hundredths() != tt.want {
+				t.Errorf("Convert(%d × %s) = %s, want %d hundredths EUR", tt.amount, tt.rate, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyConvertErrors(t *testing.T) {
+	rate, _ := ParseRate("2")
+
+	_, err := Money{Amount: NewAmount(100), Currency: "GBP"}.Convert(ExchangeRate{Base: "USD", Quote: "EUR", Rate: rate})
+	if !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Convert with another base = %v, want ErrCurrencyMismatch", err)
+	}
+
+	_, err = Money{Amount: NewAmount(1 << 62), Currency: "USD"}.Convert(ExchangeRate{Base: "USD", Quote: "EUR", Rate: rate})
+	if err == nil {
+		t.Error("Convert overflowing int64 succeeded, want error")
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	a := Money{Amount: NewAmount(1250), Currency: "EUR"}
+	b := Money{Amount: NewAmount(99), Currency: "EUR"}
+
+	sum, err := a.Add(b)
+	if err != nil || !sum.Equal(Money{Amount: NewAmount(1349), Currency: "EUR"}) {
+		t.Errorf("Add = %s, %v, want 13.49 EUR", sum, err)
+	}
+
+	diff, err := a.Sub(b)
+	if err != nil || !diff.Equal(Money{Amount: NewAmount(1151), Currency: "EUR"}) {
+		t.Errorf("Sub = %s, %v, want 11.51 EUR", diff, err)
+	}
+
+	if got := a.Mul(3); !got.Equal(Money{Amount: NewAmount(3750), Currency: "EUR"}) {
+		t.Errorf("Mul(3) = %s, want 37.50 EUR", got)
+	}
+
+	if _, err := a.Add(Money{Amount: NewAmount(1), Currency: "USD"}); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add across currencies = %v, want ErrCurrencyMismatch", err)
+	}
+}
+
+func TestMoneyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		money   Money
+		wantErr bool
+	}{
+		{name: "valid", money: Money{Amount: NewAmount(1), Currency: "USD"}},
+		{name: "zero amount", money: Money{Amount: NewAmount(0), Currency: "USD"}, wantErr: true},
+		{name: "negative amount", money: Money{Amount: NewAmount(-1), Currency: "USD"}, wantErr: true},
+		{name: "lower-case currency", money: Money{Amount: NewAmount(1), Currency: "usd"}, wantErr: true},
+		{name: "short currency", money: Money{Amount: NewAmount(1), Currency: "US"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.money.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%s) = %v, want error: %t", tt.money, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMoneyDiv(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  int64
+		n       int64
+		want    int64
+		wantErr bool
+	}{
+		{name: "exact", amount: 900, n: 3, want: 300},
+		{name: "rounds down", amount: 1000, n: 3, want: 333},
+		{name: "rounds up", amount: 2000, n: 3, want: 667},
+		{name: "half rounds away from zero", amount: 5, n: 2, want: 3},
+		{name: "negative half rounds away from zero", amount: -5, n: 2, want: -3},
+		{name: "negative rounds down", amount: -1000, n: 3, want: -333},
+		{name: "by one", amount: 1234, n: 1, want: 1234},
+		{name: "zero amount", amount: 0, n: 7, want: 0},
+		{name: "by zero", amount: 1000, n: 0, wantErr: true},
+		{name: "by negative", amount: 1000, n: -2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Money{Amount: NewAmount(tt.amount), Currency: "EUR"}.Div(tt.n)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Div(%d) = %s, want error", tt.n, got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Div(%d) error: %v", tt.n, err)
+			}
+
+			if got.Currency != "EUR" || got.Amount.Hundredths() != tt.want {
+				t.Errorf("%d / %d = %s, want %d hundredths EUR", tt.amount, tt.n, got, tt.want)
+			}
+		})
+	}
+}
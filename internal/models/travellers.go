@@ -0,0 +1,80 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Traveller is a person a user books travel for. Travellers belong to one
+// user and are named on that user's bookings: as passengers on flights,
+// guests at hotels and participants in activities.
+type Traveller struct {
+	ID             string         `json:"id"              gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserID         string         `json:"user_id"         gorm:"type:uuid;not null;index"`
+	FirstName      string         `json:"first_name"      gorm:"type:varchar;not null"`
+	LastName       string         `json:"last_name"       gorm:"type:varchar;not null"`
+	DateOfBirth    time.Time      `json:"date_of_birth"   gorm:"type:date;not null"`
+	LoyaltyNumbers LoyaltyNumbers `json:"loyalty_numbers" gorm:"type:jsonb;not null"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// LoyaltyNumber is a traveller's membership number in a frequent-flyer or
+// hotel loyalty programme.
+type LoyaltyNumber struct {
+	Program string `json:"program"`
+	Number  string `json:"number"`
+}
+
+// LoyaltyNumbers is stored as a JSONB array.
+type LoyaltyNumbers []LoyaltyNumber
+
+// Scan implements sql.Scanner for JSONB columns.
+func (l *LoyaltyNumbers) Scan(src interface{}) error {
+	var raw []byte
+
+	switch v := src.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("models: cannot scan %T into LoyaltyNumbers", src)
+	}
+
+	return json.Unmarshal(raw, l)
+}
+
+// Value implements driver.Valuer, writing the numbers as a JSON array.
+func (l LoyaltyNumbers) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+
+	raw, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(raw), nil
+}
+
+// CreateTravellerRequest is the payload for adding a traveller to a user.
+type CreateTravellerRequest struct {
+	FirstName      string          `json:"first_name"      binding:"required"`
+	LastName       string          `json:"last_name"       binding:"required"`
+	DateOfBirth    time.Time       `json:"date_of_birth"   binding:"required"`
+	LoyaltyNumbers []LoyaltyNumber `json:"loyalty_numbers"`
+}
+
+// UpdateTravellerRequest is the payload for updating a traveller.
+// All fields are optional — only provided fields will be updated. A
+// provided LoyaltyNumbers replaces the whole list.
+type UpdateTravellerRequest struct {
+	FirstName      *string          `json:"first_name"`
+	LastName       *string          `json:"last_name"`
+	DateOfBirth    *time.Time       `json:"date_of_birth"`
+	LoyaltyNumbers *[]LoyaltyNumber `json:"loyalty_numbers"`
+}
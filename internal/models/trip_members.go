@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// TripRole is a member's level of access to a trip. Roles nest: owners can
+// do everything editors can, and editors everything viewers can.
+type TripRole string
+
+const (
+	// TripRoleOwner may also delete and restore the trip and manage its
+	// members and invitations.
+	TripRoleOwner TripRole = "owner"
+
+	// TripRoleEditor may also change the trip and its bookings.
+	TripRoleEditor TripRole = "editor"
+
+	// TripRoleViewer may read the trip, its bookings and its history.
+	TripRoleViewer TripRole = "viewer"
+)
+
+// tripRoleRanks orders the roles from least to most access.
+var tripRoleRanks = map[TripRole]int{
+	TripRoleViewer: 1,
+	TripRoleEditor: 2,
+	TripRoleOwner:  3,
+}
+
+// Valid reports whether r is one of the defined roles.
+func (r TripRole) Valid() bool {
+	_, ok := tripRoleRanks[r]
+	return ok
+}
+
+// Allows reports whether r grants at least the access of required.
+func (r TripRole) Allows(required TripRole) bool {
+	return r.Valid() && tripRoleRanks[r] >= tripRoleRanks[required]
+}
+
+// TripMember gives a user a role on a trip. The trip's primary owner,
+// Trip.UserID, is always a member with TripRoleOwner.
+type TripMember struct {
+	TripID    string    `json:"trip_id"    gorm:"type:uuid;primaryKey"`
+	UserID    string    `json:"user_id"    gorm:"type:uuid;primaryKey"`
+	Role      TripRole  `json:"role"       gorm:"type:varchar;not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// InvitationStatus is where an invitation is in its lifecycle.
+type InvitationStatus string
+
+const (
+	InvitationStatusPending  InvitationStatus = "pending"
+	InvitationStatusAccepted InvitationStatus = "accepted"
+	InvitationStatusRevoked  InvitationStatus = "revoked"
+)
+
+// TripInvitation offers a user a role on a trip. Only the invitee can
+// accept it, and only while it is pending; a trip owner can revoke it until
+// then.
+type TripInvitation struct {
+	ID        string           `json:"id"         gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
+	TripID    string           `json:"trip_id"    gorm:"type:uuid;not null"`
+	InviteeID string           `json:"invitee_id" gorm:"type:uuid;not null"`
+	Role      TripRole         `json:"role"       gorm:"type:varchar;not null"`
+	Status    InvitationStatus `json:"status"     gorm:"type:varchar;not null;default:'pending'"`
+	InvitedBy string           `json:"invited_by" gorm:"type:uuid;not null"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// CreateInvitationRequest is the payload for inviting a user to a trip.
+type CreateInvitationRequest struct {
+	UserID string   `json:"user_id" binding:"required,uuid"`
+	Role   TripRole `json:"role"    binding:"required"`
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// ActivityService defines business operations for activities.
+type ActivityService interface {
+	// CreateActivity validates and persists a new activity listing.
+	CreateActivity(ctx context.Context, activity models.Activity) (*models.Activity, error)
+
+	// GetActivity retrieves a single activity by ID.
+	GetActivity(ctx context.Context, id string) (*models.Activity, error)
+
+	// ListActivities returns activities matching the provided filter parameters.
+	ListActivities(ctx context.Context, params models.ActivitySearchParams, page models.PageParams) (*models.Page[models.Activity], error)
+
+	// UpdateActivity applies partial updates to an existing activity.
+	UpdateActivity(ctx context.Context, id string, req models.UpdateActivityRequest) (*models.Activity, error)
+
+	// DeleteActivity removes an activity listing.
+	DeleteActivity(ctx context.Context, id string) error
+}
+
+// CreateActivity validates the activity data then delegates to the repository.
+func (s *TravelPlannerServiceImpl) CreateActivity(ctx context.Context, activity models.Activity) (*models.Activity, error) {
+	// Business rule: price must be a positive amount in a valid currency.
+	if err := validateMoney("activity price", "price", activity.Price); err != nil {
+		return nil, err
+	}
+
+	// Business rule: an activity must last for some amount of time.
+	if activity.DurationHours <= 0 {
+		return nil, validationError("activity duration_hours must be greater than 0", "duration_hours")
+	}
+
+	created, err := s.repo.CreateActivity(ctx, activity)
+	if err != nil {
+		return nil, fmt.Errorf("services: create activity failed: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetActivity retrieves an activity by its UUID.
+func (s *TravelPlannerServiceImpl) GetActivity(ctx context.Context, id string) (*models.Activity, error) {
+	if id == "" {
+		return nil, validationError("activity id must not be empty", "id")
+	}
+
+	activity, err := s.repo.GetActivityByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("services: get activity failed: %w", err)
+	}
+
+	return activity, nil
+}
+
+// ListActivities returns one page of activities narrowed by the provided filters.
+func (s *TravelPlannerServiceImpl) ListActivities(ctx context.Context, params models.ActivitySearchParams, page models.PageParams) (*models.Page[models.Activity], error) {
+	// Business rule: a price range must not be inverted.
+	if params.MinPrice != nil && params.MinPrice.Sign() < 0 || params.MaxPrice != nil && params.MaxPrice.Sign() < 0 {
+		return nil, validationError("min_price and max_price must not be negative", "min_price", "max_price")
+	}
+
+	if params.MinPrice != nil && params.MaxPrice != nil && params.MinPrice.Cmp(*params.MaxPrice) > 0 {
+		return nil, validationError("min_price must not be greater than max_price", "min_price")
+	}
+
+	activities, err := s.repo.GetAllActivities(ctx, params, page)
+	if err != nil {
+		return nil, fmt.Errorf("services: list activities failed: %w", err)
+	}
+
+	return activities, nil
+}
+
+// UpdateActivity builds an update map from the non-nil fields in the request
+// and applies it to the activity with the given ID.
+func (s *TravelPlannerServiceImpl) UpdateActivity(ctx context.Context, id string, req models.UpdateActivityRequest) (*models.Activity, error) {
+	if id == "" {
+		return nil, validationError("activity id must not be empty", "id")
+	}
+
+	updates := make(map[string]interface{})
+
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+
+	if req.Location != nil {
+		updates["location"] = *req.Location
+	}
+
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+
+	if req.Price != nil {
+		if err := validateMoney("activity price", "price", *req.Price); err != nil {
+			return nil, err
+		}
+
+		updates["price_amount"] = req.Price.Amount
+		updates["price_currency"] = req.Price.Currency
+	}
+
+	if req.DurationHours != nil {
+		if *req.DurationHours <= 0 {
+			return nil, validationError("activity duration_hours must be greater than 0", "duration_hours")
+		}
+
+		updates["duration_hours"] = *req.DurationHours
+	}
+
+	if req.AvailableDate != nil {
+		updates["available_date"] = *req.AvailableDate
+	}
+
+	if len(updates) == 0 {
+		// Nothing to update — return the current record as-is.
+		return s.repo.GetActivityByID(ctx, id)
+	}
+
+	// Always refresh updated_at when any field changes.
+	updates["updated_at"] = time.Now().UTC()
+
+	updated, err := s.repo.UpdateActivity(ctx, id, updates)
+	if err != nil {
+		return nil, fmt.Errorf("services: update activity failed: %w", err)
+	}
+
+	return updated, nil
+}
+
+// DeleteActivity removes the activity with the given ID.
+func (s *TravelPlannerServiceImpl) DeleteActivity(ctx context.Context, id string) error {
+	if id == "" {
+		return validationError("activity id must not be empty", "id")
+	}
+
+	if err := s.repo.DeleteActivity(ctx, id); err != nil {
+		return fmt.Errorf("services: delete activity failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// ErrForbidden is returned when the actor is known but lacks the permission
+// an operation requires.
+var ErrForbidden = &Error{
+	Code:    models.ErrorCodeForbidden,
+	Status:  http.StatusForbidden,
+	Message: "services: forbidden",
+}
+
+// AgentService defines business operations for agent–client assignments.
+type AgentService interface {
+	// AssignClient allows an agent to manage a client's trips.
+	AssignClient(ctx context.Context, agentID, clientID string) (*models.AgentClient, error)
+
+	// UnassignClient revokes an agent's access to a client's trips.
+	UnassignClient(ctx context.Context, agentID, clientID string) error
+
+	// ListClients returns the user IDs of an agent's clients.
+	ListClients(ctx context.Context, agentID string) ([]string, error)
+}
+
+// AssignClient validates the pair then records the assignment.
+func (s *TravelPlannerServiceImpl) AssignClient(ctx context.Context, agentID, clientID string) (*models.AgentClient, error) {
+	if agentID == "" || clientID == "" {
+		return nil, validationError("agent id and client id must not be empty", "agent_id", "client_id")
+	}
+
+	// Business rule: an agent always manages their own trips already.
+	if agentID == clientID {
+		return nil, validationError("an agent cannot be their own client", "client_id")
+	}
+
+	assignment, err := s.repo.AddAgentClient(ctx, agentID, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("services: assign client failed: %w", err)
+	}
+
+	return assignment, nil
+}
+
+// UnassignClient removes the assignment between an agent and a client.
+func (s *TravelPlannerServiceImpl) UnassignClient(ctx context.Context, agentID, clientID string) error {
+	if agentID == "" || clientID == "" {
+		return validationError("agent id and client id must not be empty", "agent_id", "client_id")
+	}
+
+	if err := s.repo.RemoveAgentClient(ctx, agentID, clientID); err != nil {
+		return fmt.Errorf("services: unassign client failed: %w", err)
+	}
+
+	return nil
+}
+
+// ListClients returns the client IDs assigned to an agent.
+func (s *TravelPlannerServiceImpl) ListClients(ctx context.Context, agentID string) ([]string, error) {
+	if agentID == "" {
+		return nil, validationError("agent id must not be empty", "agent_id")
+	}
+
+	clientIDs, err := s.repo.GetAgentClientIDs(ctx, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("services: list clients failed: %w", err)
+	}
+
+	return clientIDs, nil
+}
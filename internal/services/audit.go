@@ -0,0 +1,173 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// AuditService exposes the audit log of trips, bookings and the catalogue.
+type AuditService interface {
+	// GetTripHistory returns the recorded changes to one of actor's trips
+	// and its bookings and expenses.
+	GetTripHistory(ctx context.Context, actor models.Actor, tripID string, page models.PageParams) (*models.Page[models.AuditEntry], error)
+
+	// SearchAudit queries the whole audit log. Callers must hold
+	// PermissionReadAudit.
+	SearchAudit(ctx context.Context, params models.AuditSearchParams, page models.PageParams) (*models.Page[models.AuditEntry], error)
+}
+
+// systemActor is recorded as the author of changes made by background jobs.
+var systemActor = models.Actor{Role: models.RoleSystem}
+
+// auditIgnoredFields are left out of recorded changes: they change on every
+// write and say nothing about what the change was.
+var auditIgnoredFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"version":    true,
+}
+
+// GetTripHistory returns one page of a trip's audit entries, including those
+// of its bookings and expenses.
+func (s *TravelPlannerServiceImpl) GetTripHistory(ctx context.Context, actor models.Actor, tripID string, page models.PageParams) (*models.Page[models.AuditEntry], error) {
+	if tripID == "" {
+		return nil, validationError("trip id must not be empty", "id")
+	}
+
+	// Verify the trip exists so we return a 404 rather than an empty list
+	// when the caller provides an unknown trip ID, or one they do not own.
+	if _, err := s.getOwnedTrip(ctx, actor, tripID, models.TripRoleViewer); err != nil {
+		return nil, fmt.Errorf("services: trip not found: %w", err)
+	}
+
+	entries, err := s.repo.GetTripHistory(ctx, tripID, page)
+	if err != nil {
+		return nil, fmt.Errorf("services: get trip history failed: %w", err)
+	}
+
+	return entries, nil
+}
+
+// SearchAudit delegates to the repository with the provided filter params.
+func (s *TravelPlannerServiceImpl) SearchAudit(ctx context.Context, params models.AuditSearchParams, page models.PageParams) (*models.Page[models.AuditEntry], error) {
+	if !params.From.IsZero() && !params.To.IsZero() && params.To.Before(params.From) {
+		return nil, validationError("audit search to must not be before from", "to")
+	}
+
+	entries, err := s.repo.SearchAudit(ctx, params, page)
+	if err != nil {
+		return nil, fmt.Errorf("services: search audit log failed: %w", err)
+	}
+
+	return entries, nil
+}
+
+// auditTrip records a change to a trip. before is nil for creations and
+// after is nil for deletions.
+func (s *TravelPlannerServiceImpl) auditTrip(ctx context.Context, actor models.Actor, action models.AuditAction, id string, before, after interface{}) error {
+	return s.audit(ctx, actor, models.AuditEntry{
+		EntityType: models.AuditEntityTrip,
+		EntityID:   id,
+		TripID:     &id,
+		Action:     action,
+	}, before, after)
+}
+
+// auditBooking records a change to a booking against its trip.
+func (s *TravelPlannerServiceImpl) auditBooking(ctx context.Context, actor models.Actor, action models.AuditAction, booking *models.Booking, before, after interface{}) error {
+	return s.audit(ctx, actor, models.AuditEntry{
+		EntityType: models.AuditEntityBooking,
+		EntityID:   booking.ID,
+		TripID:     &booking.TripID,
+		Action:     action,
+	}, before, after)
+}
+
+// auditExpense records a change to an expense against its trip.
+func (s *TravelPlannerServiceImpl) auditExpense(ctx context.Context, actor models.Actor, action models.AuditAction, expense *models.Expense, before, after interface{}) error {
+	return s.audit(ctx, actor, models.AuditEntry{
+		EntityType: models.AuditEntityExpense,
+		EntityID:   expense.ID,
+		TripID:     &expense.TripID,
+		Action:     action,
+	}, before, after)
+}
+
+// audit fills in the actor and the field changes between before and after,
+// then appends the entry. Updates that change nothing are not recorded.
+// Call it through a service bound to the transaction making the change, so
+// the entry is written if and only if the change is.
+func (s *TravelPlannerServiceImpl) audit(ctx context.Context, actor models.Actor, entry models.AuditEntry, before, after interface{}) error {
+	changes, err := diffFields(before, after)
+	if err != nil {
+		return fmt.Errorf("services: audit %s %s: %w", entry.EntityType, entry.Action, err)
+	}
+
+	if entry.Action == models.AuditActionUpdate && len(changes) == 0 {
+		return nil
+	}
+
+	if actor.UserID != "" {
+		entry.ActorID = &actor.UserID
+	}
+
+	entry.ActorRole = actor.Role
+	entry.Changes = changes
+
+	return s.repo.AppendAudit(ctx, entry)
+}
+
+// diffFields compares the JSON encodings of before and after field by field
+// and returns the fields that differ. Either side may be nil, in which case
+// every field of the other side is reported.
+func diffFields(before, after interface{}) (models.AuditChanges, error) {
+	old, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := models.AuditChanges{}
+
+	for name, value := range old {
+		if !bytes.Equal(value, updated[name]) {
+			changes[name] = models.FieldChange{Before: value, After: updated[name]}
+		}
+	}
+
+	for name, value := range updated {
+		if _, seen := old[name]; !seen {
+			changes[name] = models.FieldChange{After: value}
+		}
+	}
+
+	for name := range auditIgnoredFields {
+		delete(changes, name)
+	}
+
+	return changes, nil
+}
+
+// auditFields splits the JSON object encoding of v into its fields. A nil
+// v, including a nil pointer, has no fields.
+func auditFields(v interface{}) (map[string]json.RawMessage, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// auditRecord stands in for an audited model.
+type auditRecord struct {
+	Title     string    `json:"title"`
+	Budget    *int      `json:"budget,omitempty"`
+	Tags      []string  `json:"tags"`
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func TestDiffFields(t *testing.T) {
+	budget := 100
+	earlier, later := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		before, after interface{}
+		want          map[string][2]string
+	}{
+		{
+			name:   "unchanged",
+			before: auditRecord{Title: "Rome", Tags: []string{"a"}},
+			after:  auditRecord{Title: "Rome", Tags: []string{"a"}},
+			want:   map[string][2]string{},
+		},
+		{
+			name:   "changed field",
+			before: auditRecord{Title: "Rome"},
+			after:  auditRecord{Title: "Paris"},
+			want:   map[string][2]string{"title": {`"Rome"`, `"Paris"`}},
+		},
+		{
+			name:   "nested value",
+			before: auditRecord{Title: "Rome", Tags: []string{"a"}},
+			after:  auditRecord{Title: "Rome", Tags: []string{"a", "b"}},
+			want:   map[string][2]string{"tags": {`["a"]`, `["a","b"]`}},
+		},
+		{
+			name:   "field appears",
+			before: auditRecord{Title: "Rome"},
+			after:  auditRecord{Title: "Rome", Budget: &budget},
+			want:   map[string][2]string{"budget": {"", "100"}},
+		},
+		{
+			name:   "field disappears",
+			before: auditRecord{Title: "Rome", Budget: &budget},
+			after:  auditRecord{Title: "Rome"},
+			want:   map[string][2]string{"budget": {"100", ""}},
+		},
+		{
+			name:   "ignored fields",
+			before: auditRecord{Title: "Rome", Version: 1, UpdatedAt: earlier},
+			after:  auditRecord{Title: "Rome", Version: 2, UpdatedAt: later},
+			want:   map[string][2]string{},
+		},
+		{
+			name:   "created",
+			before: nil,
+			after:  &auditRecord{Title: "Rome", Version: 1},
+			want:   map[string][2]string{"title": {"", `"Rome"`}, "tags": {"", "null"}},
+		},
+		{
+			name:   "deleted through a nil pointer",
+			before: &auditRecord{Title: "Rome"},
+			after:  (*models.Trip)(nil),
+			want:   map[string][2]string{"title": {`"Rome"`, ""}, "tags": {"null", ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := diffFields(tt.before, tt.after)
+			if err != nil {
+				t.Fatalf("diffFields error: %v", err)
+			}
+
+			got := map[string][2]string{}
+			for name, change := range changes {
+				got[name] = [2]string{string(change.Before), string(change.After)}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffFields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffFieldsRejectsNonObjects(t *testing.T) {
+	if _, err := diffFields(auditRecord{}, 42); err == nil {
+		t.Error("diffFields with a number succeeded, want error")
+	}
+}
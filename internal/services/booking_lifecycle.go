@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"github.com/namkatcedrickjumtock/travel-planner/persistence"
+)
+
+// ErrInvalidBookingTransition is returned when a booking cannot move from
+// its current status to the requested one.
+var ErrInvalidBookingTransition = &Error{
+	Code:    models.ErrorCodeInvalidBookingTransition,
+	Status:  http.StatusConflict,
+	Message: "services: invalid booking status transition",
+}
+
+// bookingTransitions lists, for every status, the statuses it may move to.
+// Cancelled is terminal: nothing leaves it.
+var bookingTransitions = map[models.BookingStatus][]models.BookingStatus{
+	models.BookingStatusPending:   {models.BookingStatusConfirmed, models.BookingStatusCancelled},
+	models.BookingStatusConfirmed: {models.BookingStatusCancelled},
+	models.BookingStatusCancelled: {},
+}
+
+// canTransitionBooking reports whether a booking may move from `from` to `to`.
+func canTransitionBooking(from, to models.BookingStatus) bool {
+	for _, allowed := range bookingTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ConfirmBooking moves a pending booking to confirmed.
+func (s *TravelPlannerServiceImpl) ConfirmBooking(ctx context.Context, actor models.Actor, id, reason string) (*models.Booking, error) {
+	return s.transitionBooking(ctx, actor, id, models.BookingStatusConfirmed, reason)
+}
+
+// CancelBooking moves a pending or confirmed booking to cancelled.
+func (s *TravelPlannerServiceImpl) CancelBooking(ctx context.Context, actor models.Actor, id, reason string) (*models.Booking, error) {
+	return s.transitionBooking(ctx, actor, id, models.BookingStatusCancelled, reason)
+}
+
+// GetBookingTransitions returns the status history of a booking.
+func (s *TravelPlannerServiceImpl) GetBookingTransitions(ctx context.Context, actor models.Actor, id string) ([]models.BookingTransition, error) {
+	if id == "" {
+		return nil, validationError("booking id must not be empty", "id")
+	}
+
+	// Verify the booking exists so an unknown ID yields 404, not an empty list.
+	if _, err := s.getOwnedBooking(ctx, actor, id, models.TripRoleViewer); err != nil {
+		return nil, fmt.Errorf("services: booking not found: %w", err)
+	}
+
+	transitions, err := s.repo.GetBookingTransitions(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("services: get booking transitions failed: %w", err)
+	}
+
+	return transitions, nil
+}
+
+// transitionBooking checks the move against the state machine and persists
+// it together with its audit entry. The repository re-checks the current
+// status on write, so a concurrent transition surfaces as
+// ErrInvalidBookingTransition rather than silently overwriting the other
+// request.
+func (s *TravelPlannerServiceImpl) transitionBooking(ctx context.Context, actor models.Actor, id string, to models.BookingStatus, reason string) (*models.Booking, error) {
+	if id == "" {
+		return nil, validationError("booking id must not be empty", "id")
+	}
+
+	var updated *models.Booking
+
+	err := s.withTx(ctx, func(tx *TravelPlannerServiceImpl) error {
+		var err error
+		updated, err = tx.applyBookingTransition(ctx, actor, id, to, reason)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// applyBookingTransition is the transactional body of transitionBooking; s
+// must be bound to a transaction via withTx.
+func (s *TravelPlannerServiceImpl) applyBookingTransition(ctx context.Context, actor models.Actor, id string, to models.BookingStatus, reason string) (*models.Booking, error) {
+	booking, err := s.getOwnedBooking(ctx, actor, id, models.TripRoleEditor)
+	if err != nil {
+		return nil, fmt.Errorf("services: get booking failed: %w", err)
+	}
+
+	if !canTransitionBooking(booking.Status, to) {
+		return nil, fmt.Errorf("%w: cannot move booking from %s to %s", ErrInvalidBookingTransition, booking.Status, to)
+	}
+
+	updated, err := s.repo.TransitionBookingStatus(ctx, id, booking.Status, to, reason)
+	if err != nil {
+		if errors.Is(err, persistence.ErrBookingStatusChanged) {
+			return nil, fmt.Errorf("%w: booking was modified by another request", ErrInvalidBookingTransition)
+		}
+
+		return nil, fmt.Errorf("services: transition booking failed: %w", err)
+	}
+
+	if err := s.auditBooking(ctx, actor, models.AuditActionStatusChange, updated, booking, updated); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
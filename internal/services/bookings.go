@@ -1,37 +1,131 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
 
 	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"github.com/namkatcedrickjumtock/travel-planner/persistence"
 )
 
+// ErrInsufficientInventory is returned when the booked item does not have
+// enough capacity left, e.g. a flight without enough free seats.
+var ErrInsufficientInventory = &Error{
+	Code:    models.ErrorCodeInsufficientInventory,
+	Status:  http.StatusConflict,
+	Message: "services: insufficient inventory",
+}
+
 // BookingService defines business operations for bookings.
 type BookingService interface {
-	// BookItem creates a booking linking a trip to a hotel, flight, or activity.
-	BookItem(tripID string, req models.CreateBookingRequest) (*models.Booking, error)
+	// BookItem creates a booking linking a trip actor is an editor of to a
+	// hotel, flight, or activity. Clashes with the trip's itinerary are
+	// refused on trips that enforce conflicts and returned as warnings
+	// otherwise.
+	BookItem(ctx context.Context, actor models.Actor, tripID string, req models.CreateBookingRequest) (*models.Booking, error)
+
+	// GetBooking retrieves a single booking by ID, if actor can see its trip.
+	GetBooking(ctx context.Context, actor models.Actor, id string) (*models.Booking, error)
+
+	// GetTripBookings returns all bookings associated with a trip actor can see.
+	GetTripBookings(ctx context.Context, actor models.Actor, tripID string, page models.PageParams) (*models.Page[models.Booking], error)
 
-	// GetBooking retrieves a single booking by ID.
-	GetBooking(id string) (*models.Booking, error)
+	// ConfirmBooking moves a pending booking to confirmed.
+	ConfirmBooking(ctx context.Context, actor models.Actor, id, reason string) (*models.Booking, error)
 
-	// GetTripBookings returns all bookings associated with the given trip.
-	GetTripBookings(tripID string) ([]models.Booking, error)
+	// CancelBooking moves a pending or confirmed booking to cancelled.
+	CancelBooking(ctx context.Context, actor models.Actor, id, reason string) (*models.Booking, error)
+
+	// GetBookingTransitions returns the recorded status history of a booking.
+	GetBookingTransitions(ctx context.Context, actor models.Actor, id string) ([]models.BookingTransition, error)
 }
 
 // BookItem validates the request, verifies both the trip and the referenced
-// item exist, then persists the booking.
-func (s *TravelPlannerServiceImpl) BookItem(tripID string, req models.CreateBookingRequest) (*models.Booking, error) {
+// item exist, prices the booking from the catalogue, then persists it.
+// All of that runs in one transaction with the trip and the booked item
+// locked, so neither can change or disappear between the checks and the
+// insert.
+func (s *TravelPlannerServiceImpl) BookItem(ctx context.Context, actor models.Actor, tripID string, req models.CreateBookingRequest) (*models.Booking, error) {
 	if tripID == "" {
-		return nil, fmt.Errorf("services: trip_id must not be empty")
+		return nil, validationError("trip_id must not be empty", "trip_id")
+	}
+
+	// An omitted quantity means a single room / seat / participant.
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+
+	if req.Quantity < 0 {
+		return nil, validationError(fmt.Sprintf("quantity must be >= 1, got %d", req.Quantity), "quantity")
+	}
+
+	var created *models.Booking
+
+	err := s.withTx(ctx, func(tx *TravelPlannerServiceImpl) error {
+		var err error
+		created, err = tx.bookItem(ctx, actor, tripID, req)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Verify the parent trip exists before creating a booking against it.
-	if _, err := s.repo.GetTripByID(tripID); err != nil {
+	return created, nil
+}
+
+// bookItem is the transactional body of BookItem; s must be bound to a
+// transaction via withTx.
+func (s *TravelPlannerServiceImpl) bookItem(ctx context.Context, actor models.Actor, tripID string, req models.CreateBookingRequest) (*models.Booking, error) {
+	// Verify the parent trip exists, and belongs to the caller, before
+	// creating a booking against it. The lock keeps it from being deleted
+	// or re-dated until the booking is committed.
+	trip, err := s.getOwnedTripForUpdate(ctx, actor, tripID, models.TripRoleEditor)
+	if err != nil {
 		return nil, fmt.Errorf("services: trip not found for booking: %w", err)
 	}
 
-	// Verify the referenced item exists to prevent orphaned bookings.
-	if err := s.verifyReferenceExists(req.Type, req.ReferenceID); err != nil {
+	if !isTripOpen(trip.Status) {
+		return nil, fmt.Errorf("%w: trip is %s", ErrTripNotBookable, trip.Status)
+	}
+
+	// Hotel bookings are priced per night, so the stay dates decide how many
+	// nights each room is charged for. Other booking types have no stay.
+	nights := 1
+	if req.Type == models.BookingTypeHotel {
+		if nights, err = s.validateHotelStay(ctx, trip, &req); err != nil {
+			return nil, err
+		}
+	} else if req.CheckIn != nil || req.CheckOut != nil || req.RoomTypeID != nil {
+		return nil, validationError("check_in, check_out and room_type_id only apply to hotel bookings", "check_in", "check_out", "room_type_id")
+	}
+
+	// Look up the referenced item (preventing orphaned bookings) and derive
+	// the price from it rather than trusting the client.
+	breakdown, err := s.priceBooking(ctx, req, nights)
+	if err != nil {
+		return nil, err
+	}
+
+	// A client-supplied total is optional, but when present it must agree
+	// with the server's computation so stale quotes are caught.
+	if req.TotalPrice != nil && !req.TotalPrice.Equal(breakdown.Total) {
+		return nil, fmt.Errorf("%w: client total %s does not match computed total %s",
+			ErrPriceMismatch, req.TotalPrice, breakdown.Total)
+	}
+
+	// The travellers say who the booking is for and must fit what is booked.
+	travellers, err := s.bookingTravellers(ctx, trip, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Over-budget bookings are refused on enforcing trips and flagged on the
+	// rest. The trip row is locked, so concurrent bookings are checked in turn.
+	budgetWarning, err := s.checkBudget(ctx, trip, breakdown.Total)
+	if err != nil {
 		return nil, err
 	}
 
@@ -40,24 +134,60 @@ func (s *TravelPlannerServiceImpl) BookItem(tripID string, req models.CreateBook
 		Type:        req.Type,
 		ReferenceID: req.ReferenceID,
 		Status:      models.BookingStatusPending,
-		TotalPrice:  req.TotalPrice,
+		Quantity:    req.Quantity,
+		CheckIn:     req.CheckIn,
+		CheckOut:    req.CheckOut,
+		RoomTypeID:  req.RoomTypeID,
+		TotalPrice:  breakdown.Total,
+		Travellers:  travellers,
+	}
+
+	// Clashes with the itinerary are refused on trips that enforce them and
+	// flagged on the rest, like going over budget.
+	conflictWarnings, err := s.checkConflicts(ctx, trip, booking)
+	if err != nil {
+		return nil, err
 	}
 
-	created, err := s.repo.CreateBooking(booking)
+	created, err := s.repo.CreateBooking(ctx, booking)
 	if err != nil {
+		if errors.Is(err, persistence.ErrInsufficientSeats) {
+			return nil, fmt.Errorf("%w: flight %q has fewer than %d seats available", ErrInsufficientInventory, req.ReferenceID, req.Quantity)
+		}
+
+		if errors.Is(err, persistence.ErrHotelUnavailable) {
+			return nil, fmt.Errorf("%w: hotel %q is already booked for some of the requested nights", ErrInsufficientInventory, req.ReferenceID)
+		}
+
+		if errors.Is(err, persistence.ErrRoomsUnavailable) {
+			return nil, fmt.Errorf("%w: room type %q has fewer than %d rooms free on some of the requested nights", ErrInsufficientInventory, *req.RoomTypeID, req.Quantity)
+		}
+
 		return nil, fmt.Errorf("services: create booking failed: %w", err)
 	}
 
+	if err := s.auditBooking(ctx, actor, models.AuditActionCreate, created, nil, created); err != nil {
+		return nil, err
+	}
+
+	created.PriceBreakdown = breakdown
+
+	if budgetWarning != "" {
+		created.Warnings = append(created.Warnings, budgetWarning)
+	}
+
+	created.Warnings = append(created.Warnings, conflictWarnings...)
+
 	return created, nil
 }
 
 // GetBooking retrieves a booking by its UUID.
-func (s *TravelPlannerServiceImpl) GetBooking(id string) (*models.Booking, error) {
+func (s *TravelPlannerServiceImpl) GetBooking(ctx context.Context, actor models.Actor, id string) (*models.Booking, error) {
 	if id == "" {
-		return nil, fmt.Errorf("services: booking id must not be empty")
+		return nil, validationError("booking id must not be empty", "id")
 	}
 
-	booking, err := s.repo.GetBookingByID(id)
+	booking, err := s.getOwnedBooking(ctx, actor, id, models.TripRoleViewer)
 	if err != nil {
 		return nil, fmt.Errorf("services: get booking failed: %w", err)
 	}
@@ -65,19 +195,19 @@ func (s *TravelPlannerServiceImpl) GetBooking(id string) (*models.Booking, error
 	return booking, nil
 }
 
-// GetTripBookings returns all bookings for a trip.
-func (s *TravelPlannerServiceImpl) GetTripBookings(tripID string) ([]models.Booking, error) {
+// GetTripBookings returns one page of a trip's bookings.
+func (s *TravelPlannerServiceImpl) GetTripBookings(ctx context.Context, actor models.Actor, tripID string, page models.PageParams) (*models.Page[models.Booking], error) {
 	if tripID == "" {
-		return nil, fmt.Errorf("services: trip_id must not be empty")
+		return nil, validationError("trip_id must not be empty", "trip_id")
 	}
 
 	// Verify the trip exists so we return a 404 rather than an empty list
-	// when the caller provides an unknown trip ID.
-	if _, err := s.repo.GetTripByID(tripID); err != nil {
+	// when the caller provides an unknown trip ID, or one they do not own.
+	if _, err := s.getOwnedTrip(ctx, actor, tripID, models.TripRoleViewer); err != nil {
 		return nil, fmt.Errorf("services: trip not found: %w", err)
 	}
 
-	bookings, err := s.repo.GetBookingsByTripID(tripID)
+	bookings, err := s.repo.GetBookingsByTripID(ctx, tripID, page)
 	if err != nil {
 		return nil, fmt.Errorf("services: get trip bookings failed: %w", err)
 	}
@@ -85,23 +215,17 @@ func (s *TravelPlannerServiceImpl) GetTripBookings(tripID string) ([]models.Book
 	return bookings, nil
 }
 
-// verifyReferenceExists checks that the item being booked actually exists in
-// the database, routing to the correct repository method by booking type.
-func (s *TravelPlannerServiceImpl) verifyReferenceExists(bookingType models.BookingType, referenceID string) error {
-	var err error
-
-	switch bookingType {
-	case models.BookingTypeHotel:
-		_, err = s.repo.GetHotelByID(referenceID)
-	case models.BookingTypeFlight:
-		_, err = s.repo.GetFlightByID(referenceID)
-	default:
-		return fmt.Errorf("services: unsupported booking type %q", bookingType)
+// getOwnedBooking loads a booking and checks that actor holds at least
+// `role` on its parent trip.
+func (s *TravelPlannerServiceImpl) getOwnedBooking(ctx context.Context, actor models.Actor, id string, role models.TripRole) (*models.Booking, error) {
+	booking, err := s.repo.GetBookingByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 
-	if err != nil {
-		return fmt.Errorf("services: referenced %s with id %q not found: %w", bookingType, referenceID, err)
+	if _, err := s.getOwnedTrip(ctx, actor, booking.TripID, role); err != nil {
+		return nil, err
 	}
 
-	return nil
-}
\ No newline at end of file
+	return booking, nil
+}
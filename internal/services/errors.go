@@ -0,0 +1,70 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// Error is a domain error: a failure the caller can act on, as opposed to an
+// infrastructure failure. Code tells clients what went wrong, Status is the
+// HTTP status it maps to and Fields names the request fields involved.
+//
+// The package's sentinel errors are *Error values. errors.Is matches an
+// *Error against another by code, so a sentinel wrapped with extra detail by
+// fmt.Errorf still matches, and so does a freshly built error of the same
+// kind: every validation error matches ErrValidation.
+type Error struct {
+	Code    models.ErrorCode
+	Status  int
+	Message string
+	Fields  []models.FieldError
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is an *Error with the same code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// ErrValidation matches every error returned for input that breaks a
+// business rule. Such errors list the offending fields.
+var ErrValidation = &Error{
+	Code:    models.ErrorCodeValidationFailed,
+	Status:  http.StatusUnprocessableEntity,
+	Message: "services: validation failed",
+}
+
+// validationError returns a validation error with the given message that
+// reports the message against each of fields.
+func validationError(message string, fields ...string) *Error {
+	fieldErrors := make([]models.FieldError, 0, len(fields))
+
+	for _, field := range fields {
+		fieldErrors = append(fieldErrors, models.FieldError{Field: field, Message: message})
+	}
+
+	return &Error{
+		Code:    models.ErrorCodeValidationFailed,
+		Status:  http.StatusUnprocessableEntity,
+		Message: "services: " + message,
+		Fields:  fieldErrors,
+	}
+}
+
+// validateMoney checks m with Money.Validate, reporting a failure against
+// field. What names the amount in the message, e.g. "trip budget".
+func validateMoney(what, field string, m models.Money) error {
+	if err := m.Validate(); err != nil {
+		return validationError(fmt.Sprintf("%s: %s", what, strings.TrimPrefix(err.Error(), "models: ")), field)
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrNoExchangeRate is returned when a price cannot be converted because no
+// rate for its currency pair is in effect.
+var ErrNoExchangeRate = &Error{
+	Code:    models.ErrorCodeNoExchangeRate,
+	Status:  http.StatusUnprocessableEntity,
+	Message: "services: no exchange rate available",
+}
+
+// ExchangeRateService defines operations for loading exchange rates and
+// converting prices into a display currency.
+type ExchangeRateService interface {
+	// SaveExchangeRates validates and stores a batch of rates. The batch is
+	// all-or-nothing: one invalid rate rejects the whole batch.
+	SaveExchangeRates(ctx context.Context, rates []models.ExchangeRate) ([]models.ExchangeRate, error)
+
+	// ConvertPrices fills in Converted on every price, expressing it in
+	// `currency` with the rate in effect today. Stored amounts are untouched.
+	ConvertPrices(ctx context.Context, currency string, prices ...*models.Money) error
+}
+
+// SaveExchangeRates normalises effective dates to whole days, validates each
+// rate, then upserts the batch.
+func (s *TravelPlannerServiceImpl) SaveExchangeRates(ctx context.Context, rates []models.ExchangeRate) ([]models.ExchangeRate, error) {
+	if len(rates) == 0 {
+		return nil, validationError("at least one exchange rate is required", "rates")
+	}
+
+	for i := range rates {
+		rate := &rates[i]
+
+		if !models.ValidCurrency(rate.Base) || !models.ValidCurrency(rate.Quote) {
+			return nil, validationError(fmt.Sprintf("rate %d: base and quote must be ISO-4217 codes, got %q/%q", i, rate.Base, rate.Quote), fmt.Sprintf("rates[%d].base", i), fmt.Sprintf("rates[%d].quote", i))
+		}
+
+		if rate.Base == rate.Quote {
+			return nil, validationError(fmt.Sprintf("rate %d: base and quote must differ", i), fmt.Sprintf("rates[%d].quote", i))
+		}
+
+		if !rate.Rate.IsPositive() {
+			return nil, validationError(fmt.Sprintf("rate %d: rate must be greater than 0", i), fmt.Sprintf("rates[%d].rate", i))
+		}
+
+		if rate.EffectiveDate.IsZero() {
+			return nil, validationError(fmt.Sprintf("rate %d: effective_date is required", i), fmt.Sprintf("rates[%d].effective_date", i))
+		}
+
+		rate.EffectiveDate = truncateDay(rate.EffectiveDate)
+	}
+
+	if err := s.repo.UpsertExchangeRates(ctx, rates); err != nil {
+		return nil, fmt.Errorf("services: save exchange rates failed: %w", err)
+	}
+
+	return rates, nil
+}
+
+// ConvertPrices looks each source currency up once and converts every price
+// with the rate in effect today (UTC). Nil prices are skipped.
+func (s *TravelPlannerServiceImpl) ConvertPrices(ctx context.Context, currency string, prices ...*models.Money) error {
+	if !models.ValidCurrency(currency) {
+		return validationError(fmt.Sprintf("invalid display currency %q", currency), "currency")
+	}
+
+	rates := s.newRateTable(currency)
+
+	for _, price := range prices {
+		if price == nil {
+			continue
+		}
+
+		converted, rate, err := rates.convert(ctx, *price)
+		if err != nil {
+			return err
+		}
+
+		price.Converted = &models.Conversion{
+			Amount:        converted.Amount,
+			Currency:      converted.Currency,
+			Rate:          rate.Rate,
+			EffectiveDate: rate.EffectiveDate,
+		}
+	}
+
+	return nil
+}
+
+// rateTable converts money into one target currency using the rates in
+// effect today, looking each source currency up at most once.
+type rateTable struct {
+	svc    *TravelPlannerServiceImpl
+	target string
+	asOf   time.Time
+	rates  map[string]models.ExchangeRate
+}
+
+// newRateTable returns an empty rateTable converting into target.
+func (s *TravelPlannerServiceImpl) newRateTable(target string) *rateTable {
+	return &rateTable{
+		svc:    s,
+		target: target,
+		asOf:   truncateDay(time.Now()),
+		rates:  map[string]models.ExchangeRate{},
+	}
+}
+
+// rate returns the rate from `from` into the target currency. Returns an
+// error wrapping ErrNoExchangeRate when none is in effect.
+func (t *rateTable) rate(ctx context.Context, from string) (models.ExchangeRate, error) {
+	if rate, found := t.rates[from]; found {
+		return rate, nil
+	}
+
+	rate := models.IdentityRate(t.target, t.asOf)
+
+	if from != t.target {
+		stored, err := t.svc.repo.GetExchangeRate(ctx, from, t.target, t.asOf)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ExchangeRate{}, fmt.Errorf("%w: %s to %s", ErrNoExchangeRate, from, t.target)
+		}
+
+		if err != nil {
+			return models.ExchangeRate{}, fmt.Errorf("services: get exchange rate failed: %w", err)
+		}
+
+		rate = *stored
+	}
+
+	t.rates[from] = rate
+
+	return rate, nil
+}
+
+// convert expresses m in the target currency and returns the rate used.
+func (t *rateTable) convert(ctx context.Context, m models.Money) (models.Money, models.ExchangeRate, error) {
+	rate, err := t.rate(ctx, m.Currency)
+	if err != nil {
+		return models.Money{}, models.ExchangeRate{}, err
+	}
+
+	converted, err := m.Convert(rate)
+	if err != nil {
+		return models.Money{}, models.ExchangeRate{}, fmt.Errorf("services: convert %s failed: %w", m, err)
+	}
+
+	return converted, rate, nil
+}
+
+// used returns the non-identity rates looked up so far, ordered by source
+// currency.
+func (t *rateTable) used() []models.ExchangeRate {
+	var used []models.ExchangeRate
+
+	for _, rate := range t.rates {
+		if rate.Base != rate.Quote {
+			used = append(used, rate)
+		}
+	}
+
+	sort.Slice(used, func(i, j int) bool { return used[i].Base < used[j].Base })
+
+	return used
+}
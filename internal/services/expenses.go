@@ -0,0 +1,611 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"gorm.io/gorm"
+)
+
+// maxExactSettleUp is the most members with an open balance for which
+// settleUp searches for the fewest transfers. The search is exponential in
+// the number of members; larger groups are settled greedily instead, which
+// takes at most one transfer fewer than there are members.
+const maxExactSettleUp = 16
+
+// ExpenseService defines business operations for sharing a trip's costs.
+type ExpenseService interface {
+	// RecordExpense records who paid for a booking or an ad-hoc cost on a
+	// trip actor can edit, and how it is split between the trip's members.
+	RecordExpense(ctx context.Context, actor models.Actor, tripID string, req models.CreateExpenseRequest) (*models.Expense, error)
+
+	// ListExpenses returns a page of the expenses of a trip actor can see.
+	ListExpenses(ctx context.Context, actor models.Actor, tripID string, page models.PageParams) (*models.Page[models.Expense], error)
+
+	// DeleteExpense deletes an expense of a trip actor can edit.
+	DeleteExpense(ctx context.Context, actor models.Actor, tripID, expenseID string) error
+
+	// GetTripBalances works out what every member of a trip actor can see
+	// paid and owes in `currency`, or in the budget currency when currency is
+	// empty, and the fewest transfers that settle all debts.
+	GetTripBalances(ctx context.Context, actor models.Actor, tripID, currency string) (*models.TripBalances, error)
+}
+
+// RecordExpense validates the request, splits the amount into shares and
+// persists the expense together with its audit entry. A booking's expense
+// costs the booking's total price; each booking can be paid for only once.
+func (s *TravelPlannerServiceImpl) RecordExpense(ctx context.Context, actor models.Actor, tripID string, req models.CreateExpenseRequest) (*models.Expense, error) {
+	if tripID == "" {
+		return nil, validationError("trip id must not be empty", "id")
+	}
+
+	trip, err := s.getOwnedTrip(ctx, actor, tripID, models.TripRoleEditor)
+	if err != nil {
+		return nil, fmt.Errorf("services: record expense failed: %w", err)
+	}
+
+	members, err := s.tripMemberIDs(ctx, trip)
+	if err != nil {
+		return nil, fmt.Errorf("services: record expense failed: %w", err)
+	}
+
+	if !slices.Contains(members, req.PaidBy) {
+		return nil, validationError(fmt.Sprintf("payer %q is not a member of the trip", req.PaidBy), "paid_by")
+	}
+
+	expense := models.Expense{
+		TripID:      tripID,
+		BookingID:   req.BookingID,
+		Description: strings.TrimSpace(req.Description),
+		PaidBy:      req.PaidBy,
+		SplitMethod: req.SplitMethod,
+	}
+
+	if req.BookingID != nil {
+		if req.Amount != nil {
+			return nil, validationError("a booking's expense costs the booking's total price; amount must be left out", "amount")
+		}
+
+		booking, err := s.repo.GetBookingByID(ctx, *req.BookingID)
+		if err != nil {
+			return nil, fmt.Errorf("services: record expense failed: %w", err)
+		}
+
+		if booking.TripID != tripID {
+			return nil, fmt.Errorf("services: booking %q is not on trip %q: %w", booking.ID, tripID, gorm.ErrRecordNotFound)
+		}
+
+		if booking.Status == models.BookingStatusCancelled {
+			return nil, validationError("a cancelled booking cannot be paid for", "booking_id")
+		}
+
+		expense.Amount = booking.TotalPrice
+
+		if expense.Description == "" {
+			expense.Description = fmt.Sprintf("%s booking", booking.Type)
+		}
+	} else {
+		if req.Amount == nil {
+			return nil, validationError("an expense without a booking needs an amount", "amount")
+		}
+
+		if err := validateMoney("expense amount", "amount", *req.Amount); err != nil {
+			return nil, err
+		}
+
+		if expense.Description == "" {
+			return nil, validationError("an expense without a booking needs a description", "description")
+		}
+
+		expense.Amount = models.Money{Amount: req.Amount.Amount, Currency: req.Amount.Currency}
+	}
+
+	if expense.Shares, err = splitExpense(expense.Amount, req.SplitMethod, req.Shares, members); err != nil {
+		return nil, err
+	}
+
+	var created *models.Expense
+
+	err = s.withTx(ctx, func(tx *TravelPlannerServiceImpl) error {
+		var err error
+		if created, err = tx.repo.CreateExpense(ctx, expense); err != nil {
+			return err
+		}
+
+		return tx.auditExpense(ctx, actor, models.AuditActionCreate, created, nil, created)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("services: record expense failed: %w", err)
+	}
+
+	return created, nil
+}
+
+// ListExpenses returns one page of a trip's expenses, newest first by
+// default.
+func (s *TravelPlannerServiceImpl) ListExpenses(ctx context.Context, actor models.Actor, tripID string, page models.PageParams) (*models.Page[models.Expense], error) {
+	if tripID == "" {
+		return nil, validationError("trip id must not be empty", "id")
+	}
+
+	if _, err := s.getOwnedTrip(ctx, actor, tripID, models.TripRoleViewer); err != nil {
+		return nil, fmt.Errorf("services: trip not found: %w", err)
+	}
+
+	expenses, err := s.repo.GetExpensesByTripID(ctx, tripID, page)
+	if err != nil {
+		return nil, fmt.Errorf("services: list expenses failed: %w", err)
+	}
+
+	return expenses, nil
+}
+
+// DeleteExpense removes an expense and records its audit entry in the same
+// transaction.
+func (s *TravelPlannerServiceImpl) DeleteExpense(ctx context.Context, actor models.Actor, tripID, expenseID string) error {
+	if tripID == "" || expenseID == "" {
+		return validationError("trip id and expense id must not be empty", "id", "expense_id")
+	}
+
+	if _, err := s.getOwnedTrip(ctx, actor, tripID, models.TripRoleEditor); err != nil {
+		return fmt.Errorf("services: delete expense failed: %w", err)
+	}
+
+	expense, err := s.repo.GetExpenseByID(ctx, expenseID)
+	if err != nil {
+		return fmt.Errorf("services: delete expense failed: %w", err)
+	}
+
+	if expense.TripID != tripID {
+		return fmt.Errorf("services: expense %q is not on trip %q: %w", expenseID, tripID, gorm.ErrRecordNotFound)
+	}
+
+	err = s.withTx(ctx, func(tx *TravelPlannerServiceImpl) error {
+		if err := tx.repo.DeleteExpense(ctx, expenseID); err != nil {
+			return err
+		}
+
+		return tx.auditExpense(ctx, actor, models.AuditActionDelete, expense, expense, nil)
+	})
+	if err != nil {
+		return fmt.Errorf("services: delete expense failed: %w", err)
+	}
+
+	return nil
+}
+
+// GetTripBalances converts every counted expense into one currency and nets
+// what each member paid against their shares. Every current member is
+// listed, as is anyone who has since left but still paid or owes.
+func (s *TravelPlannerServiceImpl) GetTripBalances(ctx context.Context, actor models.Actor, tripID, currency string) (*models.TripBalances, error) {
+	if tripID == "" {
+		return nil, validationError("trip id must not be empty", "id")
+	}
+
+	trip, err := s.getOwnedTrip(ctx, actor, tripID, models.TripRoleViewer)
+	if err != nil {
+		return nil, fmt.Errorf("services: get trip balances failed: %w", err)
+	}
+
+	expenses, err := s.repo.GetBalanceExpenses(ctx, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("services: get trip balances failed: %w", err)
+	}
+
+	members, err := s.tripMemberIDs(ctx, trip)
+	if err != nil {
+		return nil, fmt.Errorf("services: get trip balances failed: %w", err)
+	}
+
+	if currency == "" {
+		currencies := make([]string, 0, len(expenses))
+		for _, expense := range expenses {
+			currencies = append(currencies, expense.Amount.Currency)
+		}
+
+		if currency, err = summaryCurrency(trip, "expenses", currencies); err != nil {
+			return nil, err
+		}
+	}
+
+	rates := s.newRateTable(currency)
+
+	paid := map[string]int64{}
+	owed := map[string]int64{}
+
+	for _, member := range members {
+		paid[member] = 0
+	}
+
+	for _, expense := range expenses {
+		converted, _, err := rates.convert(ctx, expense.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		// Converting the total once and dividing it by the stored shares
+		// keeps every expense balanced to the cent in the new currency.
+		weights := make([]int64, len(expense.Shares))
+		for i, share := range expense.Shares {
+			weights[i] = share.Amount.Amount.Hundredths()
+		}
+
+		paid[expense.PaidBy] += converted.Amount.Hundredths()
+
+		for i, amount := range allocate(converted.Amount.Hundredths(), weights) {
+			owed[expense.Shares[i].UserID] += amount
+		}
+	}
+
+	net := make(map[string]int64, len(paid)+len(owed))
+
+	balances := make([]models.MemberBalance, 0, len(paid)+len(owed))
+	for userID := range unionKeys(paid, owed) {
+		net[userID] = paid[userID] - owed[userID]
+
+		balances = append(balances, models.MemberBalance{
+			UserID: userID,
+			Paid:   models.Money{Amount: models.NewAmount(paid[userID]), Currency: currency},
+			Owed:   models.Money{Amount: models.NewAmount(owed[userID]), Currency: currency},
+			Net:    models.Money{Amount: models.NewAmount(net[userID]), Currency: currency},
+		})
+	}
+
+	sort.Slice(balances, func(i, j int) bool { return balances[i].UserID < balances[j].UserID })
+
+	transfers := []models.Transfer{}
+	for _, transfer := range settleUp(net) {
+		transfers = append(transfers, models.Transfer{
+			From:   transfer.from,
+			To:     transfer.to,
+			Amount: models.Money{Amount: models.NewAmount(transfer.amount), Currency: currency},
+		})
+	}
+
+	return &models.TripBalances{
+		TripID:    tripID,
+		Currency:  currency,
+		Expenses:  len(expenses),
+		Balances:  balances,
+		Transfers: transfers,
+		Rates:     rates.used(),
+	}, nil
+}
+
+// tripMemberIDs returns the IDs of the trip's members, its primary owner
+// included, ordered by when they joined.
+func (s *TravelPlannerServiceImpl) tripMemberIDs(ctx context.Context, trip *models.Trip) ([]string, error) {
+	members, err := s.repo.GetTripMembers(ctx, trip.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{trip.UserID}
+	for _, member := range members {
+		if member.UserID != trip.UserID {
+			ids = append(ids, member.UserID)
+		}
+	}
+
+	return ids, nil
+}
+
+// splitExpense turns the requested shares into stored ones whose amounts add
+// up to total exactly. An equal split without shares is split between every
+// member of the trip.
+func splitExpense(total models.Money, method models.SplitMethod, requested []models.ExpenseShareRequest, members []string) ([]models.ExpenseShare, error) {
+	if !method.Valid() {
+		return nil, validationError(fmt.Sprintf("split_method must be equal, percentage or exact, got %q", method), "split_method")
+	}
+
+	if len(requested) == 0 {
+		if method != models.SplitEqual {
+			return nil, validationError(fmt.Sprintf("a %s split needs shares", method), "shares")
+		}
+
+		for _, member := range members {
+			requested = append(requested, models.ExpenseShareRequest{UserID: member})
+		}
+	}
+
+	weights := make([]int64, len(requested))
+	seen := make(map[string]bool, len(requested))
+	sum := int64(0)
+
+	for i, share := range requested {
+		field := fmt.Sprintf("shares[%d]", i)
+
+		if !slices.Contains(members, share.UserID) {
+			return nil, validationError(fmt.Sprintf("user %q is not a member of the trip", share.UserID), field+".user_id")
+		}
+
+		if seen[share.UserID] {
+			return nil, validationError(fmt.Sprintf("user %q has more than one share", share.UserID), field+".user_id")
+		}
+
+		seen[share.UserID] = true
+
+		switch method {
+		case models.SplitEqual:
+			if share.Percentage != nil || share.Amount != nil {
+				return nil, validationError("an equal split takes no percentage or amount", field)
+			}
+
+			weights[i] = 1
+		case models.SplitPercentage:
+			if share.Percentage == nil || share.Percentage.Sign() <= 0 {
+				return nil, validationError("a percentage split needs a percentage greater than 0 for every share", field+".percentage")
+			}
+
+			if share.Amount != nil {
+				return nil, validationError("a percentage split takes no amount", field+".amount")
+			}
+
+			weights[i] = share.Percentage.Hundredths()
+		case models.SplitExact:
+			if share.Amount == nil || share.Amount.Sign() < 0 {
+				return nil, validationError("an exact split needs an amount of at least 0 for every share", field+".amount")
+			}
+
+			if share.Percentage != nil {
+				return nil, validationError("an exact split takes no percentage", field+".percentage")
+			}
+
+			weights[i] = share.Amount.Hundredths()
+		}
+
+		sum += weights[i]
+	}
+
+	hundred := models.NewAmount(100 * 100)
+
+	if method == models.SplitPercentage && sum != hundred.Hundredths() {
+		return nil, validationError(fmt.Sprintf("percentages add up to %s, want %s", models.NewAmount(sum), hundred), "shares")
+	}
+
+	if method == models.SplitExact && sum != total.Amount.Hundredths() {
+		return nil, validationError(fmt.Sprintf("share amounts add up to %s, want %s", models.NewAmount(sum), total.Amount), "shares")
+	}
+
+	shares := make([]models.ExpenseShare, len(requested))
+
+	for i, amount := range allocate(total.Amount.Hundredths(), weights) {
+		shares[i] = models.ExpenseShare{
+			UserID: requested[i].UserID,
+			Amount: models.Money{Amount: models.NewAmount(amount), Currency: total.Currency},
+		}
+
+		if method == models.SplitPercentage {
+			shares[i].Percentage = requested[i].Percentage
+		}
+	}
+
+	return shares, nil
+}
+
+// allocate divides total hundredths in proportion to weights so that the
+// parts add up to total exactly, by the largest remainder method: every part
+// is rounded down and the hundredths left over go to the parts that lost
+// the most, earlier parts first on ties. Weights must not be negative, and
+// all-zero weights give all-zero parts.
+func allocate(total int64, weights []int64) []int64 {
+	parts := make([]int64, len(weights))
+
+	sum := int64(0)
+	for _, weight := range weights {
+		sum += weight
+	}
+
+	if sum == 0 {
+		return parts
+	}
+
+	// total × weight can exceed int64 for large converted totals.
+	remainders := make([]*big.Int, len(weights))
+	left := total
+
+	for i, weight := range weights {
+		quotient, remainder := new(big.Int).QuoRem(
+			new(big.Int).Mul(big.NewInt(total), big.NewInt(weight)), big.NewInt(sum), new(big.Int))
+
+		parts[i] = quotient.Int64()
+		remainders[i] = remainder
+		left -= parts[i]
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]].Cmp(remainders[order[b]]) > 0 })
+
+	for _, i := range order[:left] {
+		parts[i]++
+	}
+
+	return parts
+}
+
+// transfer is one payment of settleUp's plan, in hundredths.
+type transfer struct {
+	from, to string
+	amount   int64
+}
+
+// settleUp returns the fewest transfers that bring every net balance to
+// zero; positive balances are owed money, negative ones owe it, and they
+// must add up to zero.
+//
+// A group of k members whose balances add up to zero settles among itself
+// in k-1 transfers, so splitting the members into as many such groups as
+// possible minimises the total. Up to maxExactSettleUp members that split
+// is found exactly, over all subsets; beyond that everyone is one group.
+func settleUp(net map[string]int64) []transfer {
+	var users []string
+	for userID, balance := range net {
+		if balance != 0 {
+			users = append(users, userID)
+		}
+	}
+
+	sort.Strings(users)
+
+	if len(users) == 0 {
+		return nil
+	}
+
+	groups := [][]string{users}
+	if len(users) <= maxExactSettleUp {
+		groups = zeroSumGroups(users, net)
+	}
+
+	var transfers []transfer
+	for _, group := range groups {
+		transfers = append(transfers, settleGroup(group, net)...)
+	}
+
+	return transfers
+}
+
+// zeroSumGroups partitions users into as many groups with a zero total
+// balance as possible. best[mask] is the most groups the users in mask
+// split into, counting mask itself once its total is zero.
+func zeroSumGroups(users []string, net map[string]int64) [][]string {
+	n := len(users)
+	full := 1<<n - 1
+
+	sums := make([]int64, full+1)
+	best := make([]int, full+1)
+
+	for mask := 1; mask <= full; mask++ {
+		low := mask & -mask
+		sums[mask] = sums[mask^low] + net[users[bits.TrailingZeros(uint(low))]]
+
+		for i := 0; i < n; i++ {
+			if mask&(1<<i) != 0 && best[mask^(1<<i)] > best[mask] {
+				best[mask] = best[mask^(1<<i)]
+			}
+		}
+
+		if sums[mask] == 0 {
+			best[mask]++
+		}
+	}
+
+	// Walk back down from everyone, one user at a time, along the choices
+	// that gave the best count; every zero-sum set on the way closes a group.
+	var groups [][]string
+
+	mask, closed := full, full
+	for mask != 0 {
+		next := mask
+		for i := 0; i < n; i++ {
+			if mask&(1<<i) != 0 && best[mask^(1<<i)] == best[mask]-boolToInt(sums[mask] == 0) {
+				next = mask ^ (1 << i)
+				break
+			}
+		}
+
+		if sums[next] == 0 {
+			groups = append(groups, maskUsers(users, closed^next))
+			closed = next
+		}
+
+		mask = next
+	}
+
+	return groups
+}
+
+// settleGroup settles a group whose balances add up to zero in at most one
+// transfer fewer than it has members, by repeatedly having the largest
+// debtor pay the largest creditor as much as either can take.
+func settleGroup(group []string, net map[string]int64) []transfer {
+	var creditors, debtors []string
+	remaining := make(map[string]int64, len(group))
+
+	for _, userID := range group {
+		remaining[userID] = net[userID]
+
+		if net[userID] > 0 {
+			creditors = append(creditors, userID)
+		} else {
+			debtors = append(debtors, userID)
+		}
+	}
+
+	byAmount := func(users []string, sign int64) {
+		sort.SliceStable(users, func(i, j int) bool { return remaining[users[i]]*sign > remaining[users[j]]*sign })
+	}
+
+	byAmount(creditors, 1)
+	byAmount(debtors, -1)
+
+	var transfers []transfer
+
+	for c, d := 0, 0; c < len(creditors) && d < len(debtors); {
+		creditor, debtor := creditors[c], debtors[d]
+
+		amount := remaining[creditor]
+		if -remaining[debtor] < amount {
+			amount = -remaining[debtor]
+		}
+
+		transfers = append(transfers, transfer{from: debtor, to: creditor, amount: amount})
+		remaining[creditor] -= amount
+		remaining[debtor] += amount
+
+		if remaining[creditor] == 0 {
+			c++
+		}
+
+		if remaining[debtor] == 0 {
+			d++
+		}
+	}
+
+	return transfers
+}
+
+// maskUsers returns the users whose bits are set in mask.
+func maskUsers(users []string, mask int) []string {
+	var picked []string
+
+	for i, userID := range users {
+		if mask&(1<<i) != 0 {
+			picked = append(picked, userID)
+		}
+	}
+
+	return picked
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// unionKeys returns the set of keys present in either map.
+func unionKeys(a, b map[string]int64) map[string]bool {
+	keys := make(map[string]bool, len(a)+len(b))
+
+	for key := range a {
+		keys[key] = true
+	}
+
+	for key := range b {
+		keys[key] = true
+	}
+
+	return keys
+}
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int64
+		weights []int64
+		want    []int64
+	}{
+		{name: "exact", total: 900, weights: []int64{1, 1, 1}, want: []int64{300, 300, 300}},
+		{name: "remainder cent to the earliest on ties", total: 1000, weights: []int64{1, 1, 1}, want: []int64{334, 333, 333}},
+		{name: "two remainder cents", total: 1001, weights: []int64{1, 1, 1}, want: []int64{334, 334, 333}},
+		{name: "remainder to the largest fraction", total: 100, weights: []int64{1, 2}, want: []int64{33, 67}},
+		{name: "percentages 33.33/33.33/33.34", total: 1000, weights: []int64{3333, 3333, 3334}, want: []int64{333, 333, 334}},
+		{name: "percentages of a round total", total: 10000, weights: []int64{3333, 3333, 3334}, want: []int64{3333, 3333, 3334}},
+		{name: "zero-weight share", total: 101, weights: []int64{0, 1, 1}, want: []int64{0, 51, 50}},
+		{name: "exact amounts", total: 1234, weights: []int64{1000, 0, 234}, want: []int64{1000, 0, 234}},
+		{name: "all weights zero", total: 500, weights: []int64{0, 0}, want: []int64{0, 0}},
+		{name: "single part", total: 777, weights: []int64{5}, want: []int64{777}},
+		{name: "fewer cents than parts", total: 2, weights: []int64{1, 1, 1, 1}, want: []int64{1, 1, 0, 0}},
+		{name: "no parts", total: 100, weights: []int64{}, want: []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allocate(tt.total, tt.weights); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocate(%d, %v) = %v, want %v", tt.total, tt.weights, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocateLargeTotal(t *testing.T) {
+	// total × weight overflows int64 here.
+	total := int64(math.MaxInt64 / 2)
+	parts := allocate(total, []int64{3333, 3333, 3334})
+
+	sum := int64(0)
+	for _, part := range parts {
+		if part < 0 {
+			t.Fatalf("allocate gave a negative part: %v", parts)
+		}
+
+		sum += part
+	}
+
+	if sum != total {
+		t.Errorf("parts %v add up to %d, want %d", parts, sum, total)
+	}
+}
+
+func TestSplitExpense(t *testing.T) {
+	amount := func(s string) *models.Amount {
+		a, err := models.ParseAmount(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return &a
+	}
+
+	members := []string{"a", "b", "c"}
+	total := models.Money{Amount: models.NewAmount(1000), Currency: "EUR"}
+
+	tests := []struct {
+		name      string
+		method    models.SplitMethod
+		requested []models.ExpenseShareRequest
+		want      map[string]int64
+		wantErr   bool
+	}{
+		{
+			name:   "equal between all members",
+			method: models.SplitEqual,
+			want:   map[string]int64{"a": 334, "b": 333, "c": 333},
+		},
+		{
+			name:      "equal between some members",
+			method:    models.SplitEqual,
+			requested: []models.ExpenseShareRequest{{UserID: "b"}, {UserID: "c"}},
+			want:      map[string]int64{"b": 500, "c": 500},
+		},
+		{
+			name:   "percentage",
+			method: models.SplitPercentage,
+			requested: []models.ExpenseShareRequest{
+				{UserID: "a", Percentage: amount("33.33")},
+				{UserID: "b", Percentage: amount("33.33")},
+				{UserID: "c", Percentage: amount("33.34")},
+			},
+			want: map[string]int64{"a": 333, "b": 333, "c": 334},
+		},
+		{
+			name:   "exact with a zero share",
+			method: models.SplitExact,
+			requested: []models.ExpenseShareRequest{
+				{UserID: "a", Amount: amount("10.00")},
+				{UserID: "b", Amount: amount("0")},
+			},
+			want: map[string]int64{"a": 1000, "b": 0},
+		},
+		{
+			name:   "percentages not adding up to 100",
+			method: models.SplitPercentage,
+			requested: []models.ExpenseShareRequest{
+				{UserID: "a", Percentage: amount("50")},
+				{UserID: "b", Percentage: amount("49.99")},
+			},
+			wantErr: true,
+		},
+		{
+			name:      "exact amounts not adding up to the total",
+			method:    models.SplitExact,
+			requested: []models.ExpenseShareRequest{{UserID: "a", Amount: amount("9.99")}},
+			wantErr:   true,
+		},
+		{
+			name:      "share for a non-member",
+			method:    models.SplitEqual,
+			requested: []models.ExpenseShareRequest{{UserID: "z"}},
+			wantErr:   true,
+		},
+		{
+			name:      "duplicate share",
+			method:    models.SplitEqual,
+			requested: []models.ExpenseShareRequest{{UserID: "a"}, {UserID: "a"}},
+			wantErr:   true,
+		},
+		{
+			name:    "percentage split without shares",
+			method:  models.SplitPercentage,
+			wantErr: true,
+		},
+		{
+			name:    "unknown method",
+			method:  "thirds",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares, err := splitExpense(total, tt.method, tt.requested, members)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("splitExpense = %v, want error", shares)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("splitExpense error: %v", err)
+			}
+
+			got := map[string]int64{}
+			for _, share := range shares {
+				if share.Amount.Currency != total.Currency {
+					t.Errorf("share of %s is in %s, want %s", share.UserID, share.Amount.Currency, total.Currency)
+				}
+
+				got[share.UserID] = share.Amount.Amount.Hundredths()
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitExpense = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettleUp(t *testing.T) {
+	// pairs returns n users in pairs that owe each other different amounts.
+	pairs := func(n int) map[string]int64 {
+		net := map[string]int64{}
+		for i := 0; i < n; i++ {
+			net[fmt.Sprintf("creditor%02d", i)] = int64(100 * (i + 1))
+			net[fmt.Sprintf("debtor%02d", i)] = -int64(100 * (i + 1))
+		}
+
+		return net
+	}
+
+	// oneDebtor returns n creditors of 1.00 each and the one user who owes
+	// them all.
+	oneDebtor := func(n int) map[string]int64 {
+		net := map[string]int64{"debtor": -int64(100 * n)}
+		for i := 0; i < n; i++ {
+			net[fmt.Sprintf("creditor%02d", i)] = 100
+		}
+
+		return net
+	}
+
+	tests := []struct {
+		name          string
+		net           map[string]int64
+		wantTransfers int
+	}{
+		{name: "nothing owed", net: map[string]int64{}, wantTransfers: 0},
+		{name: "zero balances only", net: map[string]int64{"a": 0, "b": 0}, wantTransfers: 0},
+		{name: "one debt", net: map[string]int64{"a": 500, "b": -500}, wantTransfers: 1},
+		{name: "one debtor, two creditors", net: map[string]int64{"a": 300, "b": 200, "c": -500}, wantTransfers: 2},
+		{
+			// {b, c} and {a, d, e} each add up to zero: 1 + 2 transfers
+			// rather than the 4 that paying the largest creditor first takes.
+			name:          "fewer than n-1 transfers",
+			net:           map[string]int64{"a": 400, "b": 300, "c": -300, "d": -200, "e": -200},
+			wantTransfers: 3,
+		},
+		{name: "independent pairs", net: pairs(3), wantTransfers: 3},
+		{name: "largest exact search", net: pairs(maxExactSettleUp / 2), wantTransfers: maxExactSettleUp / 2},
+		{name: "greedy beyond the exact limit", net: oneDebtor(maxExactSettleUp), wantTransfers: maxExactSettleUp},
+		{name: "greedy pairs beyond the exact limit", net: pairs(maxExactSettleUp/2 + 1), wantTransfers: maxExactSettleUp/2 + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transfers := settleUp(tt.net)
+
+			if len(transfers) != tt.wantTransfers {
+				t.Errorf("settleUp made %d transfers, want %d: %v", len(transfers), tt.wantTransfers, transfers)
+			}
+
+			checkSettled(t, tt.net, transfers)
+		})
+	}
+}
+
+func TestSettleUpRandom(t *testing.T) {
+	// A fixed linear congruential generator keeps the cases reproducible.
+	seed := uint64(42)
+	next := func(n int) int {
+		seed = seed*6364136223846793005 + 1442695040888963407
+		return int(seed>>33) % n
+	}
+
+	for round := 0; round < 200; round++ {
+		size := 2 + next(maxExactSettleUp+4)
+		net := map[string]int64{}
+		sum := int64(0)
+
+		for i := 0; i < size-1; i++ {
+			balance := int64(next(2001) - 1000)
+			net[fmt.Sprintf("u%02d", i)] = balance
+			sum += balance
+		}
+
+		net[fmt.Sprintf("u%02d", size-1)] = -sum
+
+		transfers := settleUp(net)
+
+		open := 0
+		for _, balance := range net {
+			if balance != 0 {
+				open++
+			}
+		}
+
+		if open > 0 && len(transfers) > open-1 {
+			t.Errorf("round %d: %d transfers for %d open balances", round, len(transfers), open)
+		}
+
+		checkSettled(t, net, transfers)
+	}
+}
+
+// checkSettled fails t unless transfers are positive payments from debtors
+// to creditors that bring every balance in net to zero.
+func checkSettled(t *testing.T, net map[string]int64, transfers []transfer) {
+	t.Helper()
+
+	remaining := make(map[string]int64, len(net))
+	for userID, balance := range net {
+		remaining[userID] = balance
+	}
+
+	for _, payment := range transfers {
+		if payment.amount <= 0 || payment.from == payment.to {
+			t.Errorf("invalid transfer %+v", payment)
+		}
+
+		if net[payment.from] >= 0 || net[payment.to] <= 0 {
+			t.Errorf("transfer %+v is not from a debtor to a creditor", payment)
+		}
+
+		remaining[payment.from] += payment.amount
+		remaining[payment.to] -= payment.amount
+	}
+
+	for userID, balance := range remaining {
+		if balance != 0 {
+			t.Errorf("%s is left with %d after %v", userID, balance, transfers)
+		}
+	}
+}
+
+func TestZeroSumGroups(t *testing.T) {
+	// want is nil where several partitions are equally good; only the
+	// number of groups is checked then.
+	tests := []struct {
+		name   string
+		net    map[string]int64
+		groups int
+		want   [][]string
+	}{
+		{
+			name:   "one group",
+			net:    map[string]int64{"a": 700, "b": -300, "c": -400},
+			groups: 1,
+			want:   [][]string{{"a", "b", "c"}},
+		},
+		{
+			name:   "two pairs",
+			net:    map[string]int64{"a": 100, "b": 200, "c": -100, "d": -200},
+			groups: 2,
+			want:   [][]string{{"a", "c"}, {"b", "d"}},
+		},
+		{
+			name:   "pair and triple",
+			net:    map[string]int64{"a": 400, "b": 300, "c": -300, "d": -200, "e": -200},
+			groups: 2,
+			want:   [][]string{{"a", "d", "e"}, {"b", "c"}},
+		},
+		{
+			name:   "equal amounts",
+			net:    map[string]int64{"a": 100, "b": 100, "c": -100, "d": -100},
+			groups: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := make([]string, 0, len(tt.net))
+			for userID := range tt.net {
+				users = append(users, userID)
+			}
+
+			sort.Strings(users)
+
+			groups := zeroSumGroups(users, tt.net)
+
+			// Compare the groups in a canonical order.
+			for _, group := range groups {
+				sort.Strings(group)
+
+				sum := int64(0)
+				for _, userID := range group {
+					sum += tt.net[userID]
+				}
+
+				if sum != 0 {
+					t.Errorf("group %v adds up to %d, want 0", group, sum)
+				}
+			}
+
+			sort.Slice(groups, func(i, j int) bool { return groups[i][0] < groups[j][0] })
+
+			if len(groups) != tt.groups {
+				t.Fatalf("zeroSumGroups = %v, want %d groups", groups, tt.groups)
+			}
+
+			if tt.want != nil && !reflect.DeepEqual(groups, tt.want) {
+				t.Errorf("zeroSumGroups = %v, want %v", groups, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
@@ -8,34 +9,47 @@ import (
 
 // FlightService defines business operations for flights.
 type FlightService interface {
-	// CreateFlight validates and persists a new flight listing.
-	CreateFlight(flight models.Flight) (*models.Flight, error)
+	// CreateFlight validates and persists a new flight listing added by actor.
+	CreateFlight(ctx context.Context, actor models.Actor, flight models.Flight) (*models.Flight, error)
 
 	// GetFlight retrieves a single flight by ID.
-	GetFlight(id string) (*models.Flight, error)
+	GetFlight(ctx context.Context, id string) (*models.Flight, error)
 
 	// ListFlights returns flights, optionally filtered by origin and/or destination.
-	ListFlights(origin, destination string) ([]models.Flight, error)
+	ListFlights(ctx context.Context, origin, destination string, page models.PageParams) (*models.Page[models.Flight], error)
 }
 
 // CreateFlight validates the flight data then delegates to the repository.
-func (s *TravelPlannerServiceImpl) CreateFlight(flight models.Flight) (*models.Flight, error) {
+func (s *TravelPlannerServiceImpl) CreateFlight(ctx context.Context, actor models.Actor, flight models.Flight) (*models.Flight, error) {
 	// Business rule: arrival must be strictly after departure.
 	if !flight.ArrivalTime.After(flight.DepartureTime) {
-		return nil, fmt.Errorf("services: flight arrival_time must be after departure_time")
+		return nil, validationError("flight arrival_time must be after departure_time", "arrival_time")
 	}
 
 	// Business rule: origin and destination must differ.
 	if flight.Origin == flight.Destination {
-		return nil, fmt.Errorf("services: flight origin and destination must be different")
+		return nil, validationError("flight origin and destination must be different", "origin", "destination")
 	}
 
 	// Business rule: available seats must be non-negative.
 	if flight.SeatsAvailable < 0 {
-		return nil, fmt.Errorf("services: flight seats_available must be >= 0, got %d", flight.SeatsAvailable)
+		return nil, validationError(fmt.Sprintf("flight seats_available must be >= 0, got %d", flight.SeatsAvailable), "seats_available")
 	}
 
-	created, err := s.repo.CreateFlight(flight)
+	var created *models.Flight
+
+	err := s.withTx(ctx, func(tx *TravelPlannerServiceImpl) error {
+		var err error
+		if created, err = tx.repo.CreateFlight(ctx, flight); err != nil {
+			return err
+		}
+
+		return tx.audit(ctx, actor, models.AuditEntry{
+			EntityType: models.AuditEntityFlight,
+			EntityID:   created.ID,
+			Action:     models.AuditActionCreate,
+		}, nil, created)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("services: create flight failed: %w", err)
 	}
@@ -44,12 +58,12 @@ func (s *TravelPlannerServiceImpl) CreateFlight(flight models.Flight) (*models.F
 }
 
 // GetFlight retrieves a flight by its UUID.
-func (s *TravelPlannerServiceImpl) GetFlight(id string) (*models.Flight, error) {
+func (s *TravelPlannerServiceImpl) GetFlight(ctx context.Context, id string) (*models.Flight, error) {
 	if id == "" {
-		return nil, fmt.Errorf("services: flight id must not be empty")
+		return nil, validationError("flight id must not be empty", "id")
 	}
 
-	flight, err := s.repo.GetFlightByID(id)
+	flight, err := s.repo.GetFlightByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("services: get flight failed: %w", err)
 	}
@@ -57,12 +71,12 @@ func (s *TravelPlannerServiceImpl) GetFlight(id string) (*models.Flight, error)
 	return flight, nil
 }
 
-// ListFlights returns all flights, narrowed by origin/destination when provided.
-func (s *TravelPlannerServiceImpl) ListFlights(origin, destination string) ([]models.Flight, error) {
-	flights, err := s.repo.GetAllFlights(origin, destination)
+// ListFlights returns one page of flights, narrowed by origin/destination when provided.
+func (s *TravelPlannerServiceImpl) ListFlights(ctx context.Context, origin, destination string, page models.PageParams) (*models.Page[models.Flight], error) {
+	flights, err := s.repo.GetAllFlights(ctx, origin, destination, page)
 	if err != nil {
 		return nil, fmt.Errorf("services: list flights failed: %w", err)
 	}
 
 	return flights, nil
-}
\ No newline at end of file
+}
@@ -1,36 +1,59 @@
 package services
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"gorm.io/gorm"
 )
 
 // HotelService defines business operations for hotels.
 type HotelService interface {
-	// CreateHotel validates and persists a new hotel listing.
-	CreateHotel(hotel models.Hotel) (*models.Hotel, error)
+	// CreateHotel validates and persists a new hotel listing added by actor.
+	CreateHotel(ctx context.Context, actor models.Actor, hotel models.Hotel) (*models.Hotel, error)
 
 	// GetHotel retrieves a single hotel by ID.
-	GetHotel(id string) (*models.Hotel, error)
+	GetHotel(ctx context.Context, id string) (*models.Hotel, error)
 
-	// ListHotels returns hotels, optionally filtered by location.
-	ListHotels(location string) ([]models.Hotel, error)
+	// ListHotels returns hotels, optionally filtered by location and by
+	// availability for a check-in/check-out range.
+	ListHotels(ctx context.Context, params models.HotelSearchParams, page models.PageParams) (*models.Page[models.Hotel], error)
+
+	// CreateRoomType adds a room type to an existing hotel on behalf of actor.
+	CreateRoomType(ctx context.Context, actor models.Actor, hotelID string, roomType models.RoomType) (*models.RoomType, error)
+
+	// ListRoomTypes returns a hotel's room types, with per-range availability
+	// when check-in/check-out are provided.
+	ListRoomTypes(ctx context.Context, hotelID string, params models.RoomAvailabilityParams) ([]models.RoomType, error)
 }
 
 // CreateHotel validates the hotel data then delegates to the repository.
-func (s *TravelPlannerServiceImpl) CreateHotel(hotel models.Hotel) (*models.Hotel, error) {
-	// Business rule: price per night must be a positive value.
-	if hotel.PricePerNight <= 0 {
-		return nil, fmt.Errorf("services: hotel price_per_night must be greater than 0")
+func (s *TravelPlannerServiceImpl) CreateHotel(ctx context.Context, actor models.Actor, hotel models.Hotel) (*models.Hotel, error) {
+	// Business rule: price per night must be a positive amount in a valid currency.
+	if err := validateMoney("hotel price_per_night", "price_per_night", hotel.PricePerNight); err != nil {
+		return nil, err
 	}
 
 	// Business rule: rating must be between 0 and 5 when provided.
 	if hotel.Rating < 0 || hotel.Rating > 5 {
-		return nil, fmt.Errorf("services: hotel rating must be between 0 and 5, got %.2f", hotel.Rating)
+		return nil, validationError(fmt.Sprintf("hotel rating must be between 0 and 5, got %.2f", hotel.Rating), "rating")
 	}
 
-	created, err := s.repo.CreateHotel(hotel)
+	var created *models.Hotel
+
+	err := s.withTx(ctx, func(tx *TravelPlannerServiceImpl) error {
+		var err error
+		if created, err = tx.repo.CreateHotel(ctx, hotel); err != nil {
+			return err
+		}
+
+		return tx.audit(ctx, actor, models.AuditEntry{
+			EntityType: models.AuditEntityHotel,
+			EntityID:   created.ID,
+			Action:     models.AuditActionCreate,
+		}, nil, created)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("services: create hotel failed: %w", err)
 	}
@@ -39,12 +62,12 @@ func (s *TravelPlannerServiceImpl) CreateHotel(hotel models.Hotel) (*models.Hote
 }
 
 // GetHotel retrieves a hotel by its UUID.
-func (s *TravelPlannerServiceImpl) GetHotel(id string) (*models.Hotel, error) {
+func (s *TravelPlannerServiceImpl) GetHotel(ctx context.Context, id string) (*models.Hotel, error) {
 	if id == "" {
-		return nil, fmt.Errorf("services: hotel id must not be empty")
+		return nil, validationError("hotel id must not be empty", "id")
 	}
 
-	hotel, err := s.repo.GetHotelByID(id)
+	hotel, err := s.repo.GetHotelByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("services: get hotel failed: %w", err)
 	}
@@ -52,12 +75,89 @@ func (s *TravelPlannerServiceImpl) GetHotel(id string) (*models.Hotel, error) {
 	return hotel, nil
 }
 
-// ListHotels returns all hotels, narrowed by location when provided.
-func (s *TravelPlannerServiceImpl) ListHotels(location string) ([]models.Hotel, error) {
-	hotels, err := s.repo.GetAllHotels(location)
+// ListHotels returns one page of hotels, narrowed by location and availability when
+// provided. check_in and check_out must be supplied together.
+func (s *TravelPlannerServiceImpl) ListHotels(ctx context.Context, params models.HotelSearchParams, page models.PageParams) (*models.Page[models.Hotel], error) {
+	if params.CheckIn.IsZero() != params.CheckOut.IsZero() {
+		return nil, validationError("check_in and check_out must be provided together", "check_in", "check_out")
+	}
+
+	if !params.CheckIn.IsZero() && !params.CheckOut.After(params.CheckIn) {
+		return nil, validationError("check_out must be after check_in", "check_out")
+	}
+
+	hotels, err := s.repo.GetAllHotels(ctx, params, page)
 	if err != nil {
 		return nil, fmt.Errorf("services: list hotels failed: %w", err)
 	}
 
 	return hotels, nil
-}
\ No newline at end of file
+}
+
+// validateHotelStay checks a requested stay against the hotel's availability
+// window, its room types and the parent trip's dates, returning the number of
+// nights. Dates are compared at day granularity in UTC and are normalised to
+// midnight in req on success.
+func (s *TravelPlannerServiceImpl) validateHotelStay(ctx context.Context, trip *models.Trip, req *models.CreateBookingRequest) (int, error) {
+	if req.CheckIn == nil || req.CheckOut == nil {
+		return 0, validationError("hotel bookings require check_in and check_out", "check_in", "check_out")
+	}
+
+	in, out := truncateDay(*req.CheckIn), truncateDay(*req.CheckOut)
+	if !out.After(in) {
+		return 0, validationError("check_out must be after check_in", "check_out")
+	}
+
+	// Business rule: the stay must fall inside the trip.
+	if in.Before(truncateDay(trip.StartDate)) || out.After(truncateDay(trip.EndDate)) {
+		return 0, validationError(fmt.Sprintf("stay %s – %s is outside the trip dates %s – %s",
+			in.Format(dateLayout), out.Format(dateLayout),
+			trip.StartDate.Format(dateLayout), trip.EndDate.Format(dateLayout)), "check_in", "check_out")
+	}
+
+	hotel, err := s.repo.GetHotelByID(ctx, req.ReferenceID)
+	if err != nil {
+		return 0, fmt.Errorf("services: referenced hotel with id %q not found: %w", req.ReferenceID, err)
+	}
+
+	// Business rule: the stay must fall inside the hotel's availability
+	// window. A zero bound means the hotel is open-ended on that side.
+	if !hotel.AvailableFrom.IsZero() && in.Before(truncateDay(hotel.AvailableFrom)) {
+		return 0, validationError(fmt.Sprintf("hotel is not available before %s", hotel.AvailableFrom.Format(dateLayout)), "check_in")
+	}
+
+	if !hotel.AvailableTo.IsZero() && out.After(truncateDay(hotel.AvailableTo)) {
+		return 0, validationError(fmt.Sprintf("hotel is not available after %s", hotel.AvailableTo.Format(dateLayout)), "check_out")
+	}
+
+	roomTypes, err := s.repo.GetRoomTypesByHotelID(ctx, hotel.ID)
+	if err != nil {
+		return 0, fmt.Errorf("services: get room types failed: %w", err)
+	}
+
+	// Business rule: a hotel that sells rooms by type must be booked by
+	// type; one without room types is a single unit and takes one booking.
+	switch {
+	case len(roomTypes) > 0 && req.RoomTypeID == nil:
+		return 0, validationError(fmt.Sprintf("hotel %q sells rooms by type, room_type_id is required", hotel.ID), "room_type_id")
+	case req.RoomTypeID != nil && !hasRoomType(roomTypes, *req.RoomTypeID):
+		return 0, fmt.Errorf("services: room type %q does not belong to hotel %q: %w", *req.RoomTypeID, hotel.ID, gorm.ErrRecordNotFound)
+	case req.RoomTypeID == nil && req.Quantity != 1:
+		return 0, validationError(fmt.Sprintf("hotel %q has no room types and can only be booked as a single unit", hotel.ID), "room_type_id")
+	}
+
+	*req.CheckIn, *req.CheckOut = in, out
+
+	return int(out.Sub(in).Hours() / 24), nil
+}
+
+// hasRoomType reports whether id is among roomTypes.
+func hasRoomType(roomTypes []models.RoomType, id string) bool {
+	for _, roomType := range roomTypes {
+		if roomType.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// ErrIdempotencyKeyReused is returned when an idempotency key arrives with a
+// different request than the one it was first used for.
+var ErrIdempotencyKeyReused = &Error{
+	Code:    models.ErrorCodeIdempotencyKeyReused,
+	Status:  http.StatusUnprocessableEntity,
+	Message: "services: idempotency key was used for a different request",
+}
+
+// ErrIdempotencyKeyInProgress is returned when an idempotency key arrives
+// while the first request sent with it is still being processed.
+var ErrIdempotencyKeyInProgress = &Error{
+	Code:    models.ErrorCodeIdempotencyKeyInProgress,
+	Status:  http.StatusConflict,
+	Message: "services: a request with this idempotency key is in progress",
+}
+
+// IdempotencyService lets retried requests replay the outcome of the first
+// attempt instead of repeating its writes.
+type IdempotencyService interface {
+	// BeginIdempotentRequest claims key for actor's request identified by
+	// fingerprint. It returns nil when the caller should process the request
+	// and then call FinishIdempotentRequest, or the stored outcome to replay
+	// when the same request already completed.
+	BeginIdempotentRequest(ctx context.Context, actor models.Actor, key, fingerprint string) (*models.IdempotencyKey, error)
+
+	// FinishIdempotentRequest stores the response to replay for key.
+	FinishIdempotentRequest(ctx context.Context, actor models.Actor, key string, statusCode int, contentType string, body []byte) error
+
+	// AbandonIdempotentRequest frees key after a failure that a retry might
+	// not hit, so the retry is processed afresh.
+	AbandonIdempotentRequest(ctx context.Context, actor models.Actor, key string) error
+
+	// PurgeExpiredIdempotencyKeys removes keys past their TTL and returns how
+	// many were removed.
+	PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+}
+
+// BeginIdempotentRequest reserves the key for IdempotencyTTL, or checks the
+// request against the live record already holding it.
+func (s *TravelPlannerServiceImpl) BeginIdempotentRequest(ctx context.Context, actor models.Actor, key, fingerprint string) (*models.IdempotencyKey, error) {
+	now := time.Now().UTC()
+
+	record, reserved, err := s.repo.ReserveIdempotencyKey(ctx, models.IdempotencyKey{
+		UserID:      actor.UserID,
+		Key:         key,
+		Fingerprint: fingerprint,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.cfg.IdempotencyTTL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("services: begin idempotent request failed: %w", err)
+	}
+
+	if reserved {
+		return nil, nil
+	}
+
+	if record.Fingerprint != fingerprint {
+		return nil, fmt.Errorf("%w: key %q", ErrIdempotencyKeyReused, key)
+	}
+
+	if record.StatusCode == nil {
+		return nil, fmt.Errorf("%w: key %q", ErrIdempotencyKeyInProgress, key)
+	}
+
+	return record, nil
+}
+
+// FinishIdempotentRequest delegates to the repository.
+func (s *TravelPlannerServiceImpl) FinishIdempotentRequest(ctx context.Context, actor models.Actor, key string, statusCode int, contentType string, body []byte) error {
+	if err := s.repo.CompleteIdempotencyKey(ctx, actor.UserID, key, statusCode, contentType, body); err != nil {
+		return fmt.Errorf("services: finish idempotent request failed: %w", err)
+	}
+
+	return nil
+}
+
+// AbandonIdempotentRequest delegates to the repository.
+func (s *TravelPlannerServiceImpl) AbandonIdempotentRequest(ctx context.Context, actor models.Actor, key string) error {
+	if err := s.repo.ReleaseIdempotencyKey(ctx, actor.UserID, key); err != nil {
+		return fmt.Errorf("services: abandon idempotent request failed: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeExpiredIdempotencyKeys is run periodically by the server's background
+// job.
+func (s *TravelPlannerServiceImpl) PurgeExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	purged, err := s.repo.PurgeExpiredIdempotencyKeys(ctx, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("services: purge idempotency keys failed: %w", err)
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// ErrPriceMismatch is returned when a client-supplied total does not match
+// the price computed from the catalogue.
+var ErrPriceMismatch = &Error{
+	Code:    models.ErrorCodePriceMismatch,
+	Status:  http.StatusUnprocessableEntity,
+	Message: "services: price mismatch",
+	Fields:  []models.FieldError{{Field: "total_price", Message: "price mismatch"}},
+}
+
+// priceBooking looks up the booked item and prices the request: rooms ×
+// nights for hotels, seats for flights and participants for activities.
+// `nights` is ignored for anything but hotels. Inventory rows (flights,
+// hotels, room types) are read FOR UPDATE, so s must be bound to a
+// transaction via withTx and the price cannot change before the insert.
+func (s *TravelPlannerServiceImpl) priceBooking(ctx context.Context, req models.CreateBookingRequest, nights int) (*models.PriceBreakdown, error) {
+	var (
+		unit      string
+		unitPrice models.Money
+		units     = req.Quantity
+		err       error
+	)
+
+	switch req.Type {
+	case models.BookingTypeHotel:
+		units = req.Quantity * nights
+
+		if req.RoomTypeID != nil {
+			var roomType *models.RoomType
+			if roomType, err = s.repo.GetRoomTypeByIDForUpdate(ctx, *req.RoomTypeID); err == nil {
+				unit, unitPrice = "room-night", roomType.PricePerNight
+			}
+
+			break
+		}
+
+		var hotel *models.Hotel
+		if hotel, err = s.repo.GetHotelByIDForUpdate(ctx, req.ReferenceID); err == nil {
+			unit, unitPrice = "night", hotel.PricePerNight
+		}
+	case models.BookingTypeFlight:
+		var flight *models.Flight
+		if flight, err = s.repo.GetFlightByIDForUpdate(ctx, req.ReferenceID); err == nil {
+			unit, unitPrice = "seat", flight.Price
+		}
+	case models.BookingTypeActivity:
+		var activity *models.Activity
+		if activity, err = s.repo.GetActivityByID(ctx, req.ReferenceID); err == nil {
+			unit, unitPrice = "participant", activity.Price
+		}
+	default:
+		return nil, validationError(fmt.Sprintf("unsupported booking type %q", req.Type), "type")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("services: referenced %s with id %q not found: %w", req.Type, req.ReferenceID, err)
+	}
+
+	return &models.PriceBreakdown{
+		Unit:      unit,
+		UnitPrice: unitPrice,
+		Units:     units,
+		Total:     unitPrice.Mul(int64(units)),
+	}, nil
+}
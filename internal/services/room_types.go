@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// CreateRoomType validates the room type then attaches it to the hotel.
+func (s *TravelPlannerServiceImpl) CreateRoomType(ctx context.Context, actor models.Actor, hotelID string, roomType models.RoomType) (*models.RoomType, error) {
+	if hotelID == "" {
+		return nil, validationError("hotel id must not be empty", "hotel_id")
+	}
+
+	// Business rule: a room must sleep at least one guest.
+	if roomType.Capacity <= 0 {
+		return nil, validationError("room type capacity must be greater than 0", "capacity")
+	}
+
+	// Business rule: price per night must be a positive amount in a valid currency.
+	if err := validateMoney("room type price_per_night", "price_per_night", roomType.PricePerNight); err != nil {
+		return nil, err
+	}
+
+	// Business rule: the type must have at least one physical room.
+	if roomType.RoomCount <= 0 {
+		return nil, validationError("room type room_count must be greater than 0", "room_count")
+	}
+
+	// Verify the parent hotel exists so an unknown ID yields 404.
+	hotel, err := s.repo.GetHotelByID(ctx, hotelID)
+	if err != nil {
+		return nil, fmt.Errorf("services: hotel not found: %w", err)
+	}
+
+	// Business rule: a hotel prices all of its rooms in one currency.
+	if roomType.PricePerNight.Currency != hotel.PricePerNight.Currency {
+		return nil, validationError(fmt.Sprintf("room type currency %s must match the hotel's %s",
+			roomType.PricePerNight.Currency, hotel.PricePerNight.Currency), "price_per_night")
+	}
+
+	roomType.HotelID = hotelID
+
+	var created *models.RoomType
+
+	err = s.withTx(ctx, func(tx *TravelPlannerServiceImpl) error {
+		var err error
+		if created, err = tx.repo.CreateRoomType(ctx, roomType); err != nil {
+			return err
+		}
+
+		return tx.audit(ctx, actor, models.AuditEntry{
+			EntityType: models.AuditEntityRoomType,
+			EntityID:   created.ID,
+			Action:     models.AuditActionCreate,
+		}, nil, created)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("services: create room type failed: %w", err)
+	}
+
+	return created, nil
+}
+
+// ListRoomTypes returns a hotel's room types. When a check-in/check-out range
+// is given each room type carries the number of rooms free on every night.
+func (s *TravelPlannerServiceImpl) ListRoomTypes(ctx context.Context, hotelID string, params models.RoomAvailabilityParams) ([]models.RoomType, error) {
+	if hotelID == "" {
+		return nil, validationError("hotel id must not be empty", "hotel_id")
+	}
+
+	if params.CheckIn.IsZero() != params.CheckOut.IsZero() {
+		return nil, validationError("check_in and check_out must be provided together", "check_in", "check_out")
+	}
+
+	if !params.CheckIn.IsZero() && !params.CheckOut.After(params.CheckIn) {
+		return nil, validationError("check_out must be after check_in", "check_out")
+	}
+
+	if _, err := s.repo.GetHotelByID(ctx, hotelID); err != nil {
+		return nil, fmt.Errorf("services: hotel not found: %w", err)
+	}
+
+	roomTypes, err := s.repo.GetRoomTypesByHotelID(ctx, hotelID)
+	if err != nil {
+		return nil, fmt.Errorf("services: list room types failed: %w", err)
+	}
+
+	if params.CheckIn.IsZero() {
+		return roomTypes, nil
+	}
+
+	checkIn, checkOut := truncateDay(params.CheckIn), truncateDay(params.CheckOut)
+
+	for i := range roomTypes {
+		available, err := s.repo.GetRoomsAvailable(ctx, roomTypes[i].ID, checkIn, checkOut)
+		if err != nil {
+			return nil, fmt.Errorf("services: get room availability failed: %w", err)
+		}
+
+		roomTypes[i].RoomsAvailable = &available
+	}
+
+	return roomTypes, nil
+}
@@ -1,8 +1,12 @@
 package services
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"time"
 
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
 	"github.com/namkatcedrickjumtock/travel-planner/persistence"
 )
 
@@ -11,15 +15,42 @@ import (
 // mocked easily in handler tests.
 type Planner interface {
 	TripService
+	TripMemberService
 	HotelService
 	FlightService
+	ActivityService
 	BookingService
+	AgentService
+	TravellerService
+	ExpenseService
+	ExchangeRateService
+	AuditService
+	IdempotencyService
 }
 
+// Config carries the service-level settings.
+type Config struct {
+	// DeletedRetention is how long a soft-deleted trip can be restored
+	// before it becomes eligible for purging. Zero means DefaultDeletedRetention.
+	DeletedRetention time.Duration
+
+	// IdempotencyTTL is how long an idempotency key replays its response.
+	// Zero means DefaultIdempotencyTTL.
+	IdempotencyTTL time.Duration
+}
+
+// DefaultDeletedRetention is the restore window used when none is configured.
+const DefaultDeletedRetention = 30 * 24 * time.Hour
+
+// DefaultIdempotencyTTL is the idempotency key lifetime used when none is
+// configured.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
 // TravelPlannerServiceImpl is the concrete implementation of Planner.
 // It delegates all data access to a persistence.Repository.
 type TravelPlannerServiceImpl struct {
 	repo persistence.Repository
+	cfg  Config
 }
 
 // Ensure TravelPlannerServiceImpl satisfies Planner at compile time.
@@ -27,10 +58,72 @@ var _ Planner = (*TravelPlannerServiceImpl)(nil)
 
 // NewTravelPlannerService creates a new TravelPlannerServiceImpl.
 // Returns an error if repo is nil to catch wiring mistakes early.
-func NewTravelPlannerService(repo persistence.Repository) (*TravelPlannerServiceImpl, error) {
+func NewTravelPlannerService(repo persistence.Repository, cfg Config) (*TravelPlannerServiceImpl, error) {
 	if repo == nil {
 		return nil, fmt.Errorf("services: repository must not be nil")
 	}
 
-	return &TravelPlannerServiceImpl{repo: repo}, nil
-}
\ No newline at end of file
+	if cfg.DeletedRetention == 0 {
+		cfg.DeletedRetention = DefaultDeletedRetention
+	}
+
+	if cfg.DeletedRetention < 0 {
+		return nil, fmt.Errorf("services: deleted retention must not be negative")
+	}
+
+	if cfg.IdempotencyTTL == 0 {
+		cfg.IdempotencyTTL = DefaultIdempotencyTTL
+	}
+
+	if cfg.IdempotencyTTL < 0 {
+		return nil, fmt.Errorf("services: idempotency ttl must not be negative")
+	}
+
+	return &TravelPlannerServiceImpl{repo: repo, cfg: cfg}, nil
+}
+
+// withTx runs fn against a copy of the service whose repository is bound to
+// a single database transaction, so every repository call fn makes through
+// tx commits or rolls back together.
+func (s *TravelPlannerServiceImpl) withTx(ctx context.Context, fn func(tx *TravelPlannerServiceImpl) error) error {
+	return s.repo.WithTx(ctx, func(repo persistence.Repository) error {
+		tx := *s
+		tx.repo = repo
+
+		return fn(&tx)
+	})
+}
+
+// ErrInvalidPage is returned by list operations when the sort field or
+// cursor cannot be used. It is re-exported so the API layer does not need to
+// depend on persistence.
+var ErrInvalidPage = persistence.ErrInvalidPage
+
+// ErrVersionMismatch is returned by conditional writes when the record is no
+// longer at the version the caller read, i.e. another request changed it.
+var ErrVersionMismatch = &Error{
+	Code:    models.ErrorCodeVersionMismatch,
+	Status:  http.StatusPreconditionFailed,
+	Message: "services: record was modified by another request",
+}
+
+// AnyVersion, passed as the expected version of a conditional write, skips
+// the version check.
+const AnyVersion = 0
+
+// checkVersion returns ErrVersionMismatch unless current satisfies expected.
+func checkVersion(kind, id string, current, expected int) error {
+	if expected != AnyVersion && current != expected {
+		return fmt.Errorf("%w: %s %q is at version %d, not %d", ErrVersionMismatch, kind, id, current, expected)
+	}
+
+	return nil
+}
+
+// dateLayout is the calendar-date format used in query params and messages.
+const dateLayout = "2006-01-02"
+
+// truncateDay strips the time-of-day from t, returning UTC midnight.
+func truncateDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrTravellerInUse is returned when deleting a traveller who is named on a
+// booking that has not been cancelled.
+var ErrTravellerInUse = &Error{
+	Code:    models.ErrorCodeTravellerInUse,
+	Status:  http.StatusConflict,
+	Message: "services: traveller is named on active bookings",
+}
+
+// TravellerService defines business operations for the travellers a user
+// books for. Travellers are managed by the same callers as the user's trips.
+type TravellerService interface {
+	// CreateTraveller adds a traveller to userID.
+	CreateTraveller(ctx context.Context, actor models.Actor, userID string, req models.CreateTravellerRequest) (*models.Traveller, error)
+
+	// GetTraveller retrieves one of userID's travellers.
+	GetTraveller(ctx context.Context, actor models.Actor, userID, id string) (*models.Traveller, error)
+
+	// ListTravellers returns a page of userID's travellers.
+	ListTravellers(ctx context.Context, actor models.Actor, userID string, page models.PageParams) (*models.Page[models.Traveller], error)
+
+	// UpdateTraveller applies partial updates to one of userID's travellers.
+	UpdateTraveller(ctx context.Context, actor models.Actor, userID, id string, req models.UpdateTravellerRequest) (*models.Traveller, error)
+
+	// DeleteTraveller removes one of userID's travellers, unless they are
+	// named on an active booking.
+	DeleteTraveller(ctx context.Context, actor models.Actor, userID, id string) error
+}
+
+// CreateTraveller validates the traveller then stores it under userID.
+func (s *TravelPlannerServiceImpl) CreateTraveller(ctx context.Context, actor models.Actor, userID string, req models.CreateTravellerRequest) (*models.Traveller, error) {
+	if err := s.authorizeUser(ctx, actor, userID); err != nil {
+		return nil, fmt.Errorf("services: create traveller failed: %w", err)
+	}
+
+	traveller := models.Traveller{
+		UserID:         userID,
+		FirstName:      strings.TrimSpace(req.FirstName),
+		LastName:       strings.TrimSpace(req.LastName),
+		DateOfBirth:    truncateDay(req.DateOfBirth),
+		LoyaltyNumbers: req.LoyaltyNumbers,
+	}
+
+	if err := validateTraveller(traveller); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.CreateTraveller(ctx, traveller)
+	if err != nil {
+		return nil, fmt.Errorf("services: create traveller failed: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetTraveller retrieves a traveller by its UUID.
+func (s *TravelPlannerServiceImpl) GetTraveller(ctx context.Context, actor models.Actor, userID, id string) (*models.Traveller, error) {
+	traveller, err := s.getOwnedTraveller(ctx, actor, userID, id)
+	if err != nil {
+		return nil, fmt.Errorf("services: get traveller failed: %w", err)
+	}
+
+	return traveller, nil
+}
+
+// ListTravellers returns one page of a user's travellers.
+func (s *TravelPlannerServiceImpl) ListTravellers(ctx context.Context, actor models.Actor, userID string, page models.PageParams) (*models.Page[models.Traveller], error) {
+	if err := s.authorizeUser(ctx, actor, userID); err != nil {
+		return nil, fmt.Errorf("services: list travellers failed: %w", err)
+	}
+
+	travellers, err := s.repo.GetTravellersByUserID(ctx, userID, page)
+	if err != nil {
+		return nil, fmt.Errorf("services: list travellers failed: %w", err)
+	}
+
+	return travellers, nil
+}
+
+// UpdateTraveller builds an update map from the non-nil fields in the
+// request, validates the resulting traveller and applies the map.
+func (s *TravelPlannerServiceImpl) UpdateTraveller(ctx context.Context, actor models.Actor, userID, id string, req models.UpdateTravellerRequest) (*models.Traveller, error) {
+	traveller, err := s.getOwnedTraveller(ctx, actor, userID, id)
+	if err != nil {
+		return nil, fmt.Errorf("services: update traveller failed: %w", err)
+	}
+
+	updates := make(map[string]interface{})
+
+	if req.FirstName != nil {
+		traveller.FirstName = strings.TrimSpace(*req.FirstName)
+		updates["first_name"] = traveller.FirstName
+	}
+
+	if req.LastName != nil {
+		traveller.LastName = strings.TrimSpace(*req.LastName)
+		updates["last_name"] = traveller.LastName
+	}
+
+	if req.DateOfBirth != nil {
+		traveller.DateOfBirth = truncateDay(*req.DateOfBirth)
+		updates["date_of_birth"] = traveller.DateOfBirth
+	}
+
+	if req.LoyaltyNumbers != nil {
+		traveller.LoyaltyNumbers = *req.LoyaltyNumbers
+		updates["loyalty_numbers"] = traveller.LoyaltyNumbers
+	}
+
+	if len(updates) == 0 {
+		// Nothing to update — return the current record as-is.
+		return traveller, nil
+	}
+
+	if err := validateTraveller(*traveller); err != nil {
+		return nil, err
+	}
+
+	// Always refresh updated_at when any field changes.
+	updates["updated_at"] = time.Now().UTC()
+
+	updated, err := s.repo.UpdateTraveller(ctx, id, updates)
+	if err != nil {
+		return nil, fmt.Errorf("services: update traveller failed: %w", err)
+	}
+
+	return updated, nil
+}
+
+// DeleteTraveller removes a traveller who is not named on any pending or
+// confirmed booking.
+func (s *TravelPlannerServiceImpl) DeleteTraveller(ctx context.Context, actor models.Actor, userID, id string) error {
+	err := s.withTx(ctx, func(tx *TravelPlannerServiceImpl) error {
+		// The lock keeps a concurrent booking from naming the traveller
+		// between the check and the delete.
+		if _, err := tx.getOwnedTravellerForUpdate(ctx, actor, userID, id); err != nil {
+			return err
+		}
+
+		booked, err := tx.repo.TravellerHasActiveBookings(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if booked {
+			return fmt.Errorf("%w: cancel the bookings naming traveller %q first", ErrTravellerInUse, id)
+		}
+
+		return tx.repo.DeleteTraveller(ctx, id)
+	})
+	if err != nil {
+		return fmt.Errorf("services: delete traveller failed: %w", err)
+	}
+
+	return nil
+}
+
+// getOwnedTraveller loads one of userID's travellers, checking that actor
+// may manage userID. Travellers of other users are reported as missing.
+func (s *TravelPlannerServiceImpl) getOwnedTraveller(ctx context.Context, actor models.Actor, userID, id string) (*models.Traveller, error) {
+	if id == "" {
+		return nil, validationError("traveller id must not be empty", "id")
+	}
+
+	if err := s.authorizeUser(ctx, actor, userID); err != nil {
+		return nil, err
+	}
+
+	traveller, err := s.repo.GetTravellerByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return ownedTraveller(traveller, userID)
+}
+
+// getOwnedTravellerForUpdate is getOwnedTraveller with the traveller row
+// locked until the surrounding transaction ends. s must be bound to a
+// transaction via withTx.
+func (s *TravelPlannerServiceImpl) getOwnedTravellerForUpdate(ctx context.Context, actor models.Actor, userID, id string) (*models.Traveller, error) {
+	if id == "" {
+		return nil, validationError("traveller id must not be empty", "id")
+	}
+
+	if err := s.authorizeUser(ctx, actor, userID); err != nil {
+		return nil, err
+	}
+
+	traveller, err := s.repo.GetTravellerByIDForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return ownedTraveller(traveller, userID)
+}
+
+// ownedTraveller returns traveller if it belongs to userID and a not-found
+// error otherwise.
+func ownedTraveller(traveller *models.Traveller, userID string) (*models.Traveller, error) {
+	if traveller.UserID != userID {
+		return nil, fmt.Errorf("services: traveller %q does not belong to user %q: %w", traveller.ID, userID, gorm.ErrRecordNotFound)
+	}
+
+	return traveller, nil
+}
+
+// authorizeUser returns a not-found error unless actor may manage userID's
+// trips, and with them their travellers.
+func (s *TravelPlannerServiceImpl) authorizeUser(ctx context.Context, actor models.Actor, userID string) error {
+	if userID == "" {
+		return validationError("user id must not be empty", "user_id")
+	}
+
+	allowed, err := s.canActFor(ctx, actor, userID)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return fmt.Errorf("services: user %q is not managed by the caller: %w", userID, gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+// validateTraveller checks the business rules of a traveller about to be
+// stored.
+func validateTraveller(traveller models.Traveller) error {
+	if traveller.FirstName == "" {
+		return validationError("traveller first_name must not be blank", "first_name")
+	}
+
+	if traveller.LastName == "" {
+		return validationError("traveller last_name must not be blank", "last_name")
+	}
+
+	if traveller.DateOfBirth.After(truncateDay(time.Now())) {
+		return validationError("traveller date_of_birth cannot be in the future", "date_of_birth")
+	}
+
+	programs := make(map[string]bool, len(traveller.LoyaltyNumbers))
+
+	for i, loyalty := range traveller.LoyaltyNumbers {
+		if strings.TrimSpace(loyalty.Program) == "" || strings.TrimSpace(loyalty.Number) == "" {
+			return validationError(fmt.Sprintf("loyalty number %d needs a program and a number", i),
+				fmt.Sprintf("loyalty_numbers[%d]", i))
+		}
+
+		if programs[loyalty.Program] {
+			return validationError(fmt.Sprintf("traveller has more than one %s loyalty number", loyalty.Program),
+				fmt.Sprintf("loyalty_numbers[%d].program", i))
+		}
+
+		programs[loyalty.Program] = true
+	}
+
+	return nil
+}
+
+// bookingTravellers checks the travellers a booking request names and
+// returns them in request order. They must be distinct travellers of the
+// trip's owner or members; flights need one per seat, activities one per
+// participant when any are named, and room-type hotel bookings no more than
+// the rooms sleep. It runs after priceBooking, so the booked room type exists.
+// The travellers stay locked until the booking's transaction ends.
+func (s *TravelPlannerServiceImpl) bookingTravellers(ctx context.Context, trip *models.Trip, req models.CreateBookingRequest) ([]models.Traveller, error) {
+	count := len(req.TravellerIDs)
+
+	switch req.Type {
+	case models.BookingTypeFlight:
+		if count != req.Quantity {
+			return nil, validationError(fmt.Sprintf("flight bookings need one traveller per seat: %d seats, %d travellers", req.Quantity, count), "traveller_ids")
+		}
+	case models.BookingTypeActivity:
+		if count > 0 && count != req.Quantity {
+			return nil, validationError(fmt.Sprintf("activity bookings need one traveller per participant: %d participants, %d travellers", req.Quantity, count), "traveller_ids")
+		}
+	case models.BookingTypeHotel:
+		if count > 0 && req.RoomTypeID != nil {
+			roomType, err := s.repo.GetRoomTypeByID(ctx, *req.RoomTypeID)
+			if err != nil {
+				return nil, fmt.Errorf("services: room type not found: %w", err)
+			}
+
+			if guests := roomType.Capacity * req.Quantity; count > guests {
+				return nil, validationError(fmt.Sprintf("%d %s rooms sleep at most %d guests, got %d travellers", req.Quantity, roomType.Name, guests, count), "traveller_ids")
+			}
+		}
+	}
+
+	if count == 0 {
+		return nil, nil
+	}
+
+	// Locked so none of them can be deleted before the booking naming them
+	// is committed.
+	travellers, err := s.repo.GetTravellersByIDsForUpdate(ctx, req.TravellerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("services: get booking travellers failed: %w", err)
+	}
+
+	members, err := s.repo.GetTripMembers(ctx, trip.ID)
+	if err != nil {
+		return nil, fmt.Errorf("services: get booking travellers failed: %w", err)
+	}
+
+	onTrip := map[string]bool{trip.UserID: true}
+	for _, member := range members {
+		onTrip[member.UserID] = true
+	}
+
+	byID := make(map[string]models.Traveller, len(travellers))
+	for _, traveller := range travellers {
+		byID[traveller.ID] = traveller
+	}
+
+	listed := make([]models.Traveller, 0, count)
+	seen := make(map[string]bool, count)
+
+	for i, id := range req.TravellerIDs {
+		field := fmt.Sprintf("traveller_ids[%d]", i)
+
+		if seen[id] {
+			return nil, validationError(fmt.Sprintf("traveller %q is listed more than once", id), field)
+		}
+
+		traveller, found := byID[id]
+		if !found || !onTrip[traveller.UserID] {
+			return nil, validationError(fmt.Sprintf("traveller %q does not belong to a member of the trip", id), field)
+		}
+
+		seen[id] = true
+		listed = append(listed, traveller)
+	}
+
+	return listed, nil
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// ErrBudgetExceeded is returned by BookItem when a booking would take a trip
+// that enforces its budget over that budget.
+var ErrBudgetExceeded = &Error{
+	Code:    models.ErrorCodeBudgetExceeded,
+	Status:  http.StatusConflict,
+	Message: "services: trip budget exceeded",
+}
+
+// ErrCurrencyRequired is returned when a summary currency cannot be inferred:
+// the trip has no budget and its bookings are in more than one currency.
+var ErrCurrencyRequired = &Error{
+	Code:    models.ErrorCodeCurrencyRequired,
+	Status:  http.StatusUnprocessableEntity,
+	Message: "services: a summary currency is required",
+}
+
+// GetTripSummary totals the trip's non-cancelled bookings by type in
+// `currency`, or in the budget currency when currency is empty, and compares
+// the total against the budget.
+func (s *TravelPlannerServiceImpl) GetTripSummary(ctx context.Context, actor models.Actor, id, currency string) (*models.TripSummary, error) {
+	if id == "" {
+		return nil, validationError("trip id must not be empty", "id")
+	}
+
+	trip, err := s.getOwnedTrip(ctx, actor, id, models.TripRoleViewer)
+	if err != nil {
+		return nil, fmt.Errorf("services: get trip summary failed: %w", err)
+	}
+
+	spend, err := s.repo.GetTripSpend(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("services: get trip summary failed: %w", err)
+	}
+
+	if currency == "" {
+		currencies := make([]string, 0, len(spend))
+		for _, line := range spend {
+			currencies = append(currencies, line.Currency)
+		}
+
+		if currency, err = summaryCurrency(trip, "bookings", currencies); err != nil {
+			return nil, err
+		}
+	}
+
+	rates := s.newRateTable(currency)
+
+	spent, byType, bookings, err := sumSpend(ctx, rates, spend)
+	if err != nil {
+		return nil, err
+	}
+
+	days := tripDays(trip)
+
+	average, err := spent.Div(int64(days))
+	if err != nil {
+		return nil, fmt.Errorf("services: get trip summary failed: %w", err)
+	}
+
+	summary := &models.TripSummary{
+		TripID:             trip.ID,
+		Currency:           currency,
+		Bookings:           bookings,
+		Spent:              spent,
+		ByType:             byType,
+		Days:               days,
+		AverageSpendPerDay: average,
+	}
+
+	if trip.Budget != nil {
+		budget, _, err := rates.convert(ctx, *trip.Budget)
+		if err != nil {
+			return nil, err
+		}
+
+		remaining, err := budget.Sub(spent)
+		if err != nil {
+			return nil, fmt.Errorf("services: get trip summary failed: %w", err)
+		}
+
+		summary.Budget = &budget
+		summary.Remaining = &remaining
+		summary.OverBudget = remaining.Amount.Sign() < 0
+	}
+
+	summary.Rates = rates.used()
+
+	return summary, nil
+}
+
+// checkBudget works out whether adding `total` to the trip's bookings takes
+// it over budget. It returns a warning for trips that only track their
+// budget and ErrBudgetExceeded for trips that enforce it. Trips without a
+// budget always pass.
+func (s *TravelPlannerServiceImpl) checkBudget(ctx context.Context, trip *models.Trip, total models.Money) (string, error) {
+	if trip.Budget == nil {
+		return "", nil
+	}
+
+	rates := s.newRateTable(trip.Budget.Currency)
+
+	spend, err := s.repo.GetTripSpend(ctx, trip.ID)
+	if err != nil {
+		return "", fmt.Errorf("services: check trip budget failed: %w", err)
+	}
+
+	spent, _, _, err := sumSpend(ctx, rates, spend)
+
+	var added models.Money
+	if err == nil {
+		added, _, err = rates.convert(ctx, total)
+	}
+
+	// Without a rate the budget cannot be checked: that blocks enforcing
+	// trips but is only worth a warning for the others.
+	if err != nil {
+		if trip.EnforceBudget {
+			return "", fmt.Errorf("services: cannot check trip budget: %w", err)
+		}
+
+		return fmt.Sprintf("trip budget not checked: %v", err), nil
+	}
+
+	after, err := spent.Add(added)
+	if err != nil {
+		return "", fmt.Errorf("services: check trip budget failed: %w", err)
+	}
+
+	if after.Amount.Cmp(trip.Budget.Amount) <= 0 {
+		return "", nil
+	}
+
+	message := fmt.Sprintf("booking brings trip spend to %s, over its budget of %s", after, trip.Budget)
+
+	if trip.EnforceBudget {
+		return "", fmt.Errorf("%w: %s", ErrBudgetExceeded, message)
+	}
+
+	return message, nil
+}
+
+// summaryCurrency picks the currency a summary is reported in when the
+// client did not ask for one: the budget's, else the single currency of the
+// summed amounts, else USD for a trip with nothing to sum. `what` names the
+// amounts in the error returned when they mix currencies.
+func summaryCurrency(trip *models.Trip, what string, currencies []string) (string, error) {
+	if trip.Budget != nil {
+		return trip.Budget.Currency, nil
+	}
+
+	currency := ""
+
+	for _, code := range currencies {
+		if currency != "" && code != currency {
+			return "", fmt.Errorf("%w: %s are in %s and %s", ErrCurrencyRequired, what, currency, code)
+		}
+
+		currency = code
+	}
+
+	if currency == "" {
+		currency = "USD"
+	}
+
+	return currency, nil
+}
+
+// sumSpend converts every spend line into the rate table's currency and
+// totals them overall and per booking type. Every booking type is present
+// in the returned map, with a zero total when nothing of it is booked.
+func sumSpend(ctx context.Context, rates *rateTable, spend []models.BookingSpend) (models.Money, map[models.BookingType]models.Money, int, error) {
+	zero := models.Money{Currency: rates.target}
+
+	spent := zero
+	byType := map[models.BookingType]models.Money{
+		models.BookingTypeHotel:    zero,
+		models.BookingTypeFlight:   zero,
+		models.BookingTypeActivity: zero,
+	}
+	bookings := 0
+
+	for _, line := range spend {
+		converted, _, err := rates.convert(ctx, models.Money{Amount: line.Amount, Currency: line.Currency})
+		if err != nil {
+			return zero, nil, 0, err
+		}
+
+		// Both sums are in rates.target, so Add cannot mismatch.
+		spent, _ = spent.Add(converted)
+		byType[line.Type], _ = byType[line.Type].Add(converted)
+		bookings += line.Bookings
+	}
+
+	return spent, byType, bookings, nil
+}
+
+// tripDays is the number of calendar days a trip spans, counting both the
+// start and the end day.
+func tripDays(trip *models.Trip) int {
+	return int(truncateDay(trip.EndDate).Sub(truncateDay(trip.StartDate)).Hours()/24) + 1
+}
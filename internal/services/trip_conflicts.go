@@ -0,0 +1,365 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// ErrItineraryConflict is returned by BookItem when a booking would clash
+// with the itinerary of a trip that enforces conflicts.
+var ErrItineraryConflict = &Error{
+	Code:    models.ErrorCodeItineraryConflict,
+	Status:  http.StatusConflict,
+	Message: "services: booking conflicts with the trip itinerary",
+}
+
+// timeLayout is how conflict messages print the times of flights and
+// activities.
+const timeLayout = "2006-01-02 15:04"
+
+// scheduledBooking is a booking with the time it takes up: the flight from
+// departure to arrival, the activity for its duration.
+type scheduledBooking struct {
+	booking    models.Booking
+	start, end time.Time
+	what       string
+}
+
+// GetTripConflicts checks a trip's pending and confirmed bookings against
+// each other and against the trip's dates.
+func (s *TravelPlannerServiceImpl) GetTripConflicts(ctx context.Context, actor models.Actor, id string) (*models.TripConflicts, error) {
+	if id == "" {
+		return nil, validationError("trip id must not be empty", "id")
+	}
+
+	trip, err := s.getOwnedTrip(ctx, actor, id, models.TripRoleViewer)
+	if err != nil {
+		return nil, fmt.Errorf("services: get trip conflicts failed: %w", err)
+	}
+
+	bookings, err := s.repo.GetActiveBookingsByTripID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("services: get trip conflicts failed: %w", err)
+	}
+
+	catalogue, err := s.loadItineraryCatalogue(ctx, bookings)
+	if err != nil {
+		return nil, fmt.Errorf("services: get trip conflicts failed: %w", err)
+	}
+
+	result := &models.TripConflicts{TripID: id, Conflicts: detectConflicts(trip, bookings, catalogue)}
+
+	for _, conflict := range result.Conflicts {
+		if conflict.Severity == models.ConflictSeverityError {
+			result.Errors++
+		} else {
+			result.Warnings++
+		}
+	}
+
+	return result, nil
+}
+
+// checkConflicts works out which conflicts `booking`, not yet stored, would
+// add to the trip's itinerary. Errors are refused on trips that enforce
+// conflicts; otherwise every conflict comes back as a warning message.
+// Accommodation gaps are left out: a new booking only ever narrows them.
+func (s *TravelPlannerServiceImpl) checkConflicts(ctx context.Context, trip *models.Trip, booking models.Booking) ([]string, error) {
+	bookings, err := s.repo.GetActiveBookingsByTripID(ctx, trip.ID)
+	if err != nil {
+		return nil, fmt.Errorf("services: check conflicts failed: %w", err)
+	}
+
+	// The new booking has no ID yet; its conflicts are the ones naming "".
+	booking.ID = ""
+	bookings = append(bookings, booking)
+
+	catalogue, err := s.loadItineraryCatalogue(ctx, bookings)
+	if err != nil {
+		return nil, fmt.Errorf("services: check conflicts failed: %w", err)
+	}
+
+	var warnings []string
+
+	for _, conflict := range detectConflicts(trip, bookings, catalogue) {
+		if conflict.Kind == models.ConflictAccommodationGap || !slices.Contains(conflict.BookingIDs, "") {
+			continue
+		}
+
+		if trip.EnforceConflicts && conflict.Severity == models.ConflictSeverityError {
+			return nil, fmt.Errorf("%w: %s", ErrItineraryConflict, conflict.Message)
+		}
+
+		warnings = append(warnings, conflict.Message)
+	}
+
+	return warnings, nil
+}
+
+// detectConflicts runs every itinerary check over the bookings. Bookings
+// whose catalogue item is gone and activities without a date are skipped,
+// as their times are unknown; the itinerary lists them as unscheduled.
+func detectConflicts(trip *models.Trip, bookings []models.Booking, catalogue *itineraryCatalogue) []models.Conflict {
+	conflicts := []models.Conflict{}
+
+	firstDay, lastDay := truncateDay(trip.StartDate), truncateDay(trip.EndDate)
+	tripEnd := lastDay.AddDate(0, 0, 1)
+
+	var scheduled, flights []scheduledBooking
+	var stays []models.Booking
+
+	for _, booking := range bookings {
+		switch booking.Type {
+		case models.BookingTypeFlight:
+			if flight := catalogue.flights[booking.ReferenceID]; flight != nil {
+				entry := scheduledBooking{
+					booking: booking,
+					start:   flight.DepartureTime,
+					end:     flight.ArrivalTime,
+					what: fmt.Sprintf("%s flight %s → %s departing %s", flight.Airline, flight.Origin, flight.Destination,
+						flight.DepartureTime.UTC().Format(timeLayout)),
+				}
+
+				scheduled, flights = append(scheduled, entry), append(flights, entry)
+			}
+		case models.BookingTypeActivity:
+			if activity := catalogue.activities[booking.ReferenceID]; activity != nil && !activity.AvailableDate.IsZero() {
+				scheduled = append(scheduled, scheduledBooking{
+					booking: booking,
+					start:   activity.AvailableDate,
+					end:     activity.AvailableDate.Add(time.Duration(activity.DurationHours * float64(time.Hour))),
+					what:    fmt.Sprintf("activity %q at %s", activity.Name, activity.AvailableDate.UTC().Format(timeLayout)),
+				})
+			}
+		case models.BookingTypeHotel:
+			if booking.CheckIn != nil && booking.CheckOut != nil {
+				stays = append(stays, booking)
+			}
+		}
+	}
+
+	sort.SliceStable(scheduled, func(i, j int) bool { return scheduled[i].start.Before(scheduled[j].start) })
+	sort.SliceStable(flights, func(i, j int) bool { return flights[i].start.Before(flights[j].start) })
+
+	// Bookings outside the trip window. Flights only need to leave within
+	// it: the flight home may well land the day after the trip ends.
+	for _, entry := range scheduled {
+		outside := entry.start.Before(firstDay) || entry.end.After(tripEnd)
+		if entry.booking.Type == models.BookingTypeFlight {
+			outside = entry.start.Before(firstDay) || !entry.start.Before(tripEnd)
+		}
+
+		if outside {
+			conflicts = append(conflicts, models.Conflict{
+				Kind:       models.ConflictOutsideTrip,
+				Severity:   models.ConflictSeverityError,
+				Message:    fmt.Sprintf("%s is outside the trip dates %s – %s", entry.what, firstDay.Format(dateLayout), lastDay.Format(dateLayout)),
+				BookingIDs: []string{entry.booking.ID},
+			})
+		}
+	}
+
+	for _, stay := range stays {
+		if truncateDay(*stay.CheckIn).Before(firstDay) || truncateDay(*stay.CheckOut).After(lastDay) {
+			conflicts = append(conflicts, models.Conflict{
+				Kind:     models.ConflictOutsideTrip,
+				Severity: models.ConflictSeverityError,
+				Message: fmt.Sprintf("%s is outside the trip dates %s – %s", describeStay(stay, catalogue),
+					firstDay.Format(dateLayout), lastDay.Format(dateLayout)),
+				BookingIDs: []string{stay.ID},
+			})
+		}
+	}
+
+	// Flights and activities at the same time for the same travellers.
+	// scheduled is sorted by start, so once a later booking starts after this
+	// one ends, none overlap it. Bookings of the same flight or activity, e.g.
+	// for different travellers, go together.
+	for i, a := range scheduled {
+		for _, b := range scheduled[i+1:] {
+			if !b.start.Before(a.end) {
+				break
+			}
+
+			if a.booking.ReferenceID == b.booking.ReferenceID || !sharesTraveller(a.booking, b.booking) {
+				continue
+			}
+
+			conflicts = append(conflicts, models.Conflict{
+				Kind:       models.ConflictOverlap,
+				Severity:   models.ConflictSeverityError,
+				Message:    fmt.Sprintf("%s overlaps %s", a.what, b.what),
+				BookingIDs: []string{a.booking.ID, b.booking.ID},
+			})
+		}
+	}
+
+	// Stays at two different hotels on the same night for the same
+	// travellers. Several bookings at one hotel, e.g. of different room
+	// types, are a group sharing it.
+	for i, a := range stays {
+		for _, b := range stays[i+1:] {
+			if a.ReferenceID == b.ReferenceID || !a.CheckIn.Before(*b.CheckOut) || !b.CheckIn.Before(*a.CheckOut) ||
+				!sharesTraveller(a, b) {
+				continue
+			}
+
+			conflicts = append(conflicts, models.Conflict{
+				Kind:       models.ConflictOverlap,
+				Severity:   models.ConflictSeverityError,
+				Message:    fmt.Sprintf("%s overlaps %s", describeStay(a, catalogue), describeStay(b, catalogue)),
+				BookingIDs: []string{a.ID, b.ID},
+			})
+		}
+	}
+
+	// Flights that leave from somewhere the traveller's previous one did not
+	// land. Members flying in from different cities are no conflict, and two
+	// bookings of the same flight are not a connection.
+	reported := map[[2]string]bool{}
+
+	for _, journey := range journeys(flights) {
+		for i := 1; i < len(journey.flights); i++ {
+			prev, next := journey.flights[i-1], journey.flights[i]
+			if prev.booking.ReferenceID == next.booking.ReferenceID {
+				continue
+			}
+
+			landed := strings.TrimSpace(catalogue.flights[prev.booking.ReferenceID].Destination)
+			leaves := strings.TrimSpace(catalogue.flights[next.booking.ReferenceID].Origin)
+
+			pair := [2]string{prev.booking.ID, next.booking.ID}
+			if strings.EqualFold(landed, leaves) || reported[pair] {
+				continue
+			}
+
+			reported[pair] = true
+
+			previous := "the previous flight"
+			if journey.traveller != nil {
+				previous = fmt.Sprintf("%s %s's previous flight", journey.traveller.FirstName, journey.traveller.LastName)
+			}
+
+			conflicts = append(conflicts, models.Conflict{
+				Kind:       models.ConflictImpossibleConnection,
+				Severity:   models.ConflictSeverityWarning,
+				Message:    fmt.Sprintf("%s leaves from %s, but %s lands in %s", next.what, leaves, previous, landed),
+				BookingIDs: []string{prev.booking.ID, next.booking.ID},
+			})
+		}
+	}
+
+	// Runs of nights with nowhere to sleep. The last day has no night.
+	sleeping := sleepingNights(bookings, catalogue)
+
+	for night := firstDay; night.Before(lastDay); night = night.AddDate(0, 0, 1) {
+		if sleeping[night] {
+			continue
+		}
+
+		from, to := night, night
+		for to.AddDate(0, 0, 1).Before(lastDay) && !sleeping[to.AddDate(0, 0, 1)] {
+			to = to.AddDate(0, 0, 1)
+		}
+
+		message := fmt.Sprintf("no accommodation for the night of %s", from.Format(dateLayout))
+		if to.After(from) {
+			message = fmt.Sprintf("no accommodation for the nights of %s – %s", from.Format(dateLayout), to.Format(dateLayout))
+		}
+
+		conflicts = append(conflicts, models.Conflict{
+			Kind:     models.ConflictAccommodationGap,
+			Severity: models.ConflictSeverityWarning,
+			Message:  message,
+			From:     &from,
+			To:       &to,
+		})
+
+		night = to
+	}
+
+	return conflicts
+}
+
+// journey is the flights one traveller takes, in departure order. traveller
+// is nil for the journey of a trip whose flights name no travellers.
+type journey struct {
+	traveller *models.Traveller
+	flights   []scheduledBooking
+}
+
+// journeys follows each traveller named on flights through the flights they
+// take; a flight naming no travellers is taken by all of them. When no
+// flight names anyone, all flights make up one journey. flights must be
+// sorted by departure.
+func journeys(flights []scheduledBooking) []journey {
+	var travellers []models.Traveller
+	seen := map[string]bool{}
+
+	for _, flight := range flights {
+		for _, traveller := range flight.booking.Travellers {
+			if !seen[traveller.ID] {
+				seen[traveller.ID] = true
+				travellers = append(travellers, traveller)
+			}
+		}
+	}
+
+	if len(travellers) == 0 {
+		return []journey{{flights: flights}}
+	}
+
+	result := make([]journey, 0, len(travellers))
+
+	for i := range travellers {
+		followed := journey{traveller: &travellers[i]}
+
+		for _, flight := range flights {
+			if len(flight.booking.Travellers) == 0 || namesTraveller(flight.booking, travellers[i].ID) {
+				followed.flights = append(followed.flights, flight)
+			}
+		}
+
+		result = append(result, followed)
+	}
+
+	return result
+}
+
+// sharesTraveller reports whether a and b may be for the same person. A
+// booking naming no travellers is taken to be for everyone on the trip.
+func sharesTraveller(a, b models.Booking) bool {
+	if len(a.Travellers) == 0 || len(b.Travellers) == 0 {
+		return true
+	}
+
+	for _, traveller := range a.Travellers {
+		if namesTraveller(b, traveller.ID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// namesTraveller reports whether booking names the traveller with the ID.
+func namesTraveller(booking models.Booking, travellerID string) bool {
+	return slices.ContainsFunc(booking.Travellers, func(t models.Traveller) bool { return t.ID == travellerID })
+}
+
+// describeStay names a hotel booking in conflict messages.
+func describeStay(stay models.Booking, catalogue *itineraryCatalogue) string {
+	name := "hotel " + stay.ReferenceID
+	if hotel := catalogue.hotels[stay.ReferenceID]; hotel != nil {
+		name = fmt.Sprintf("hotel %q", hotel.Name)
+	}
+
+	return fmt.Sprintf("stay at %s from %s to %s", name, stay.CheckIn.UTC().Format(dateLayout), stay.CheckOut.UTC().Format(dateLayout))
+}
@@ -0,0 +1,384 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"github.com/namkatcedrickjumtock/travel-planner/persistence"
+)
+
+func TestDetectConflicts(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2024, 6, 1, hour, 0, 0, 0, time.UTC) }
+
+	// A one-day trip has no nights, so no accommodation gaps get in the way.
+	trip := &models.Trip{ID: "trip", StartDate: at(0), EndDate: at(0)}
+
+	catalogue := &itineraryCatalogue{
+		flights: map[string]*models.Flight{
+			"lhr-fco": {ID: "lhr-fco", Airline: "A", Origin: "LHR", Destination: "FCO", DepartureTime: at(8), ArrivalTime: at(11)},
+			"cdg-fco": {ID: "cdg-fco", Airline: "B", Origin: "CDG", Destination: "FCO", DepartureTime: at(9), ArrivalTime: at(11)},
+			"fco-nap": {ID: "fco-nap", Airline: "C", Origin: "FCO", Destination: "NAP", DepartureTime: at(13), ArrivalTime: at(14)},
+			"mxp-nap": {ID: "mxp-nap", Airline: "D", Origin: " mxp ", Destination: "NAP", DepartureTime: at(15), ArrivalTime: at(16)},
+		},
+		activities: map[string]*models.Activity{
+			"tour":    {ID: "tour", Name: "Tour", AvailableDate: at(10), DurationHours: 2},
+			"undated": {ID: "undated", Name: "Cooking class", DurationHours: 3},
+		},
+	}
+
+	ann := models.Traveller{ID: "ann", FirstName: "Ann", LastName: "Lee"}
+	bob := models.Traveller{ID: "bob", FirstName: "Bob", LastName: "Ray"}
+
+	booking := func(id string, kind models.BookingType, reference string, travellers ...models.Traveller) models.Booking {
+		return models.Booking{ID: id, Type: kind, ReferenceID: reference, Travellers: travellers}
+	}
+
+	flight := func(id, reference string, travellers ...models.Traveller) models.Booking {
+		return booking(id, models.BookingTypeFlight, reference, travellers...)
+	}
+
+	tests := []struct {
+		name     string
+		bookings []models.Booking
+		want     []string
+	}{
+		{
+			name:     "same flight booked twice",
+			bookings: []models.Booking{flight("1", "lhr-fco"), flight("2", "lhr-fco")},
+		},
+		{
+			name:     "same flight booked twice, then a connection",
+			bookings: []models.Booking{flight("1", "lhr-fco"), flight("2", "lhr-fco"), flight("3", "fco-nap")},
+		},
+		{
+			name:     "same flight for different travellers",
+			bookings: []models.Booking{flight("1", "lhr-fco", ann), flight("2", "lhr-fco", bob), flight("3", "fco-nap", ann, bob)},
+		},
+		{
+			name:     "members flying in from different cities",
+			bookings: []models.Booking{flight("1", "lhr-fco", ann), flight("2", "cdg-fco", bob), flight("3", "fco-nap", ann, bob)},
+		},
+		{
+			name:     "one traveller on overlapping flights",
+			bookings: []models.Booking{flight("1", "lhr-fco", ann), flight("2", "cdg-fco", ann, bob)},
+			want:     []string{"overlap 1,2", "impossible_connection 1,2"},
+		},
+		{
+			name:     "overlapping flights without travellers",
+			bookings: []models.Booking{flight("1", "lhr-fco"), flight("2", "cdg-fco")},
+			want:     []string{"overlap 1,2", "impossible_connection 1,2"},
+		},
+		{
+			name:     "booking without travellers is for everyone",
+			bookings: []models.Booking{flight("1", "lhr-fco", ann), booking("2", models.BookingTypeActivity, "tour")},
+			want:     []string{"overlap 1,2"},
+		},
+		{
+			name:     "activity for someone else",
+			bookings: []models.Booking{flight("1", "lhr-fco", ann), booking("2", models.BookingTypeActivity, "tour", bob)},
+		},
+		{
+			name:     "activity without a date",
+			bookings: []models.Booking{flight("1", "lhr-fco", ann), booking("2", models.BookingTypeActivity, "undated", ann)},
+		},
+		{
+			name: "broken connection reported once for a shared journey",
+			bookings: []models.Booking{
+				flight("1", "fco-nap", ann, bob),
+				flight("2", "mxp-nap", ann, bob),
+			},
+			want: []string{"impossible_connection 1,2"},
+		},
+		{
+			name: "broken connection of one traveller",
+			bookings: []models.Booking{
+				flight("1", "lhr-fco", ann),
+				flight("2", "cdg-fco", bob),
+				flight("3", "fco-nap", ann),
+				flight("4", "mxp-nap", bob),
+			},
+			want: []string{"impossible_connection 2,4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, conflict := range detectConflicts(trip, tt.bookings, catalogue) {
+				got = append(got, string(conflict.Kind)+" "+strings.Join(conflict.BookingIDs, ","))
+			}
+
+			sort.Strings(got)
+			sort.Strings(tt.want)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectConflicts = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectConflictsNamesTraveller(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2024, 6, 1, hour, 0, 0, 0, time.UTC) }
+
+	trip := &models.Trip{ID: "trip", StartDate: at(0), EndDate: at(0)}
+	catalogue := &itineraryCatalogue{
+		flights: map[string]*models.Flight{
+			"lhr-fco": {Airline: "A", Origin: "LHR", Destination: "FCO", DepartureTime: at(8), ArrivalTime: at(11)},
+			"mxp-nap": {Airline: "B", Origin: "MXP", Destination: "NAP", DepartureTime: at(15), ArrivalTime: at(16)},
+		},
+	}
+
+	ann := models.Traveller{ID: "ann", FirstName: "Ann", LastName: "Lee"}
+	bookings := []models.Booking{
+		{ID: "1", Type: models.BookingTypeFlight, ReferenceID: "lhr-fco", Travellers: []models.Traveller{ann}},
+		{ID: "2", Type: models.BookingTypeFlight, ReferenceID: "mxp-nap", Travellers: []models.Traveller{ann}},
+	}
+
+	conflicts := detectConflicts(trip, bookings, catalogue)
+	if len(conflicts) != 1 || !strings.Contains(conflicts[0].Message, "Ann Lee's previous flight lands in FCO") {
+		t.Errorf("detectConflicts = %+v, want one connection naming Ann Lee", conflicts)
+	}
+}
+
+func TestDetectConflictsOverTripDays(t *testing.T) {
+	day := func(d, hour int) time.Time { return time.Date(2024, 6, d, hour, 0, 0, 0, time.UTC) }
+
+	// Four days and three nights: 1, 2 and 3 June.
+	trip := &models.Trip{ID: "trip", StartDate: day(1, 0), EndDate: day(4, 0)}
+
+	catalogue := &itineraryCatalogue{
+		hotels: map[string]*models.Hotel{
+			"roma": {ID: "roma", Name: "Roma"},
+			"sole": {ID: "sole", Name: "Sole"},
+		},
+		flights: map[string]*models.Flight{
+			"out":  {ID: "out", Airline: "A", Origin: "LHR", Destination: "FCO", DepartureTime: day(1, 8), ArrivalTime: day(1, 11)},
+			"home": {ID: "home", Airline: "A", Origin: "FCO", Destination: "LHR", DepartureTime: day(4, 22), ArrivalTime: day(5, 1)},
+			"late": {ID: "late", Airline: "B", Origin: "FCO", Destination: "LHR", DepartureTime: day(5, 9), ArrivalTime: day(5, 12)},
+		},
+		activities: map[string]*models.Activity{
+			"early": {ID: "early", Name: "Tour", AvailableDate: day(0, 10), DurationHours: 2},
+		},
+	}
+
+	ann := models.Traveller{ID: "ann", FirstName: "Ann", LastName: "Lee"}
+	bob := models.Traveller{ID: "bob", FirstName: "Bob", LastName: "Ray"}
+
+	stay := func(id, hotel string, checkIn, checkOut int, travellers ...models.Traveller) models.Booking {
+		in, out := day(checkIn, 0), day(checkOut, 0)
+		return models.Booking{ID: id, Type: models.BookingTypeHotel, ReferenceID: hotel, CheckIn: &in, CheckOut: &out, Travellers: travellers}
+	}
+
+	booking := func(id string, kind models.BookingType, reference string) models.Booking {
+		return models.Booking{ID: id, Type: kind, ReferenceID: reference}
+	}
+
+	tests := []struct {
+		name     string
+		bookings []models.Booking
+		want     []string
+	}{
+		{
+			name: "no accommodation at all",
+			want: []string{"accommodation_gap 2024-06-01..2024-06-03: no accommodation for the nights of 2024-06-01 – 2024-06-03"},
+		},
+		{
+			name:     "one night without accommodation",
+			bookings: []models.Booking{stay("1", "roma", 1, 2), stay("2", "roma", 3, 4)},
+			want:     []string{"accommodation_gap 2024-06-02..2024-06-02: no accommodation for the night of 2024-06-02"},
+		},
+		{
+			name:     "gaps either side of a stay",
+			bookings: []models.Booking{stay("1", "roma", 2, 3)},
+			want: []string{
+				"accommodation_gap 2024-06-01..2024-06-01: no accommodation for the night of 2024-06-01",
+				"accommodation_gap 2024-06-03..2024-06-03: no accommodation for the night of 2024-06-03",
+			},
+		},
+		{
+			name:     "overnight flight covers its night",
+			bookings: []models.Booking{stay("1", "roma", 1, 3), booking("2", models.BookingTypeFlight, "home")},
+			want:     []string{"accommodation_gap 2024-06-03..2024-06-03: no accommodation for the night of 2024-06-03"},
+		},
+		{
+			name:     "flight home leaving on the last day",
+			bookings: []models.Booking{stay("1", "roma", 1, 4), booking("2", models.BookingTypeFlight, "out"), booking("3", models.BookingTypeFlight, "home")},
+		},
+		{
+			name:     "flight leaving after the trip",
+			bookings: []models.Booking{stay("1", "roma", 1, 4), booking("2", models.BookingTypeFlight, "late")},
+			want:     []string{"outside_trip 2"},
+		},
+		{
+			name:     "activity before the trip",
+			bookings: []models.Booking{stay("1", "roma", 1, 4), booking("2", models.BookingTypeActivity, "early")},
+			want:     []string{"outside_trip 2"},
+		},
+		{
+			name:     "stay checking out after the trip",
+			bookings: []models.Booking{stay("1", "roma", 1, 5)},
+			want:     []string{"outside_trip 1"},
+		},
+		{
+			name:     "stays at two hotels on the same night",
+			bookings: []models.Booking{stay("1", "roma", 1, 3, ann), stay("2", "sole", 2, 4, ann, bob)},
+			want:     []string{"overlap 1,2"},
+		},
+		{
+			name:     "stays without travellers at two hotels",
+			bookings: []models.Booking{stay("1", "roma", 1, 4), stay("2", "sole", 1, 4)},
+			want:     []string{"overlap 1,2"},
+		},
+		{
+			name:     "group sharing a hotel",
+			bookings: []models.Booking{stay("1", "roma", 1, 4, ann), stay("2", "roma", 1, 4, ann)},
+		},
+		{
+			name:     "travellers at different hotels",
+			bookings: []models.Booking{stay("1", "roma", 1, 4, ann), stay("2", "sole", 1, 4, bob)},
+		},
+		{
+			name:     "moving hotel on the day of check-out",
+			bookings: []models.Booking{stay("1", "roma", 1, 2, ann), stay("2", "sole", 2, 4, ann)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, conflict := range detectConflicts(trip, tt.bookings, catalogue) {
+				if conflict.Kind == models.ConflictAccommodationGap {
+					got = append(got, fmt.Sprintf("%s %s..%s: %s", conflict.Kind,
+						conflict.From.Format(dateLayout), conflict.To.Format(dateLayout), conflict.Message))
+					continue
+				}
+
+				got = append(got, string(conflict.Kind)+" "+strings.Join(conflict.BookingIDs, ","))
+			}
+
+			sort.Strings(got)
+			sort.Strings(tt.want)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectConflicts = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeItineraryRepository serves a trip's bookings and the catalogue items
+// they refer to. The rest of persistence.Repository is left nil.
+type fakeItineraryRepository struct {
+	persistence.Repository
+	bookings   []models.Booking
+	flights    []models.Flight
+	activities []models.Activity
+}
+
+func (f *fakeItineraryRepository) GetActiveBookingsByTripID(context.Context, string) ([]models.Booking, error) {
+	return slices.Clone(f.bookings), nil
+}
+
+func (f *fakeItineraryRepository) GetHotelsByIDs(context.Context, []string) ([]models.Hotel, error) {
+	return nil, nil
+}
+
+func (f *fakeItineraryRepository) GetRoomTypesByIDs(context.Context, []string) ([]models.RoomType, error) {
+	return nil, nil
+}
+
+func (f *fakeItineraryRepository) GetFlightsByIDs(context.Context, []string) ([]models.Flight, error) {
+	return f.flights, nil
+}
+
+func (f *fakeItineraryRepository) GetActivitiesByIDs(context.Context, []string) ([]models.Activity, error) {
+	return f.activities, nil
+}
+
+func TestCheckConflicts(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2024, 6, 1, hour, 0, 0, 0, time.UTC) }
+
+	ann := models.Traveller{ID: "ann", FirstName: "Ann", LastName: "Lee"}
+	bob := models.Traveller{ID: "bob", FirstName: "Bob", LastName: "Ray"}
+
+	// Bob's connection is already broken; only what the new booking adds
+	// counts. The trip has nights without a hotel, which never count.
+	svc := &TravelPlannerServiceImpl{repo: &fakeItineraryRepository{
+		bookings: []models.Booking{
+			{ID: "1", Type: models.BookingTypeFlight, ReferenceID: "lhr-fco", Travellers: []models.Traveller{ann}},
+			{ID: "2", Type: models.BookingTypeFlight, ReferenceID: "cdg-fco", Travellers: []models.Traveller{bob}},
+			{ID: "3", Type: models.BookingTypeFlight, ReferenceID: "mxp-nap", Travellers: []models.Traveller{bob}},
+		},
+		flights: []models.Flight{
+			{ID: "lhr-fco", Airline: "A", Origin: "LHR", Destination: "FCO", DepartureTime: at(8), ArrivalTime: at(11)},
+			{ID: "cdg-fco", Airline: "B", Origin: "CDG", Destination: "FCO", DepartureTime: at(9), ArrivalTime: at(11)},
+			{ID: "fco-nap", Airline: "C", Origin: "FCO", Destination: "NAP", DepartureTime: at(13), ArrivalTime: at(14)},
+			{ID: "mxp-nap", Airline: "D", Origin: "MXP", Destination: "NAP", DepartureTime: at(15), ArrivalTime: at(16)},
+		},
+		activities: []models.Activity{
+			{ID: "tour", Name: "Tour", AvailableDate: at(10), DurationHours: 2},
+		},
+	}}
+
+	tests := []struct {
+		name         string
+		booking      models.Booking
+		enforce      bool
+		wantWarnings []string
+		wantErr      bool
+	}{
+		{
+			name:    "fits the itinerary",
+			booking: models.Booking{Type: models.BookingTypeFlight, ReferenceID: "fco-nap", Travellers: []models.Traveller{ann}},
+			enforce: true,
+		},
+		{
+			name:         "overlap is a warning",
+			booking:      models.Booking{Type: models.BookingTypeActivity, ReferenceID: "tour", Travellers: []models.Traveller{ann}},
+			wantWarnings: []string{`A flight LHR → FCO departing 2024-06-01 08:00 overlaps activity "Tour" at 2024-06-01 10:00`},
+		},
+		{
+			name:    "overlap is refused when enforced",
+			booking: models.Booking{Type: models.BookingTypeActivity, ReferenceID: "tour", Travellers: []models.Traveller{ann}},
+			enforce: true,
+			wantErr: true,
+		},
+		{
+			name:         "broken connection is only ever a warning",
+			booking:      models.Booking{ID: "stale", Type: models.BookingTypeFlight, ReferenceID: "mxp-nap", Travellers: []models.Traveller{ann}},
+			enforce:      true,
+			wantWarnings: []string{"D flight MXP → NAP departing 2024-06-01 15:00 leaves from MXP, but Ann Lee's previous flight lands in FCO"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trip := &models.Trip{ID: "trip", StartDate: at(0), EndDate: at(0).AddDate(0, 0, 2), EnforceConflicts: tt.enforce}
+
+			warnings, err := svc.checkConflicts(context.Background(), trip, tt.booking)
+			if tt.wantErr {
+				if !errors.Is(err, ErrItineraryConflict) {
+					t.Fatalf("checkConflicts = %q, %v, want ErrItineraryConflict", warnings, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("checkConflicts error: %v", err)
+			}
+
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("checkConflicts = %q, want %q", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// itineraryCatalogue holds the catalogue items a trip's bookings refer to,
+// by ID.
+type itineraryCatalogue struct {
+	hotels     map[string]*models.Hotel
+	roomTypes  map[string]*models.RoomType
+	flights    map[string]*models.Flight
+	activities map[string]*models.Activity
+}
+
+// GetTripItinerary lays a trip's pending and confirmed bookings out over its
+// days. The booked hotels, room types, flights and activities are loaded
+// with one query per kind rather than one per booking.
+func (s *TravelPlannerServiceImpl) GetTripItinerary(ctx context.Context, actor models.Actor, id string) (*models.Itinerary, error) {
+	if id == "" {
+		return nil, validationError("trip id must not be empty", "id")
+	}
+
+	trip, err := s.getOwnedTrip(ctx, actor, id, models.TripRoleViewer)
+	if err != nil {
+		return nil, fmt.Errorf("services: get trip itinerary failed: %w", err)
+	}
+
+	bookings, err := s.repo.GetActiveBookingsByTripID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("services: get trip itinerary failed: %w", err)
+	}
+
+	catalogue, err := s.loadItineraryCatalogue(ctx, bookings)
+	if err != nil {
+		return nil, fmt.Errorf("services: get trip itinerary failed: %w", err)
+	}
+
+	start := truncateDay(trip.StartDate)
+
+	itinerary := &models.Itinerary{
+		Trip:        *trip,
+		Days:        make([]models.ItineraryDay, tripDays(trip)),
+		Unscheduled: []models.ItineraryItem{},
+	}
+
+	for i := range itinerary.Days {
+		itinerary.Days[i] = models.ItineraryDay{Date: start.AddDate(0, 0, i), Items: []models.ItineraryItem{}}
+	}
+
+	// place files an item under its day. Items outside the trip are kept as
+	// unscheduled, except the middle days of a stay, which would only repeat
+	// its check-in.
+	place := func(day time.Time, item models.ItineraryItem) {
+		i := int(day.Sub(start).Hours() / 24)
+		if day.Before(start) || i >= len(itinerary.Days) {
+			if item.Event != models.ItineraryEventStay {
+				itinerary.Unscheduled = append(itinerary.Unscheduled, item)
+			}
+
+			return
+		}
+
+		itinerary.Days[i].Items = append(itinerary.Days[i].Items, item)
+	}
+
+	for _, booking := range bookings {
+		item := models.ItineraryItem{Booking: booking}
+
+		switch booking.Type {
+		case models.BookingTypeFlight:
+			item.Event = models.ItineraryEventFlight
+			item.Flight = catalogue.flights[booking.ReferenceID]
+
+			if item.Flight == nil {
+				itinerary.Unscheduled = append(itinerary.Unscheduled, item)
+				continue
+			}
+
+			item.Starts, item.Ends = &item.Flight.DepartureTime, &item.Flight.ArrivalTime
+
+			place(truncateDay(item.Flight.DepartureTime), item)
+		case models.BookingTypeHotel:
+			item.Hotel = catalogue.hotels[booking.ReferenceID]
+			if booking.RoomTypeID != nil {
+				item.RoomType = catalogue.roomTypes[*booking.RoomTypeID]
+			}
+
+			if booking.CheckIn == nil || booking.CheckOut == nil {
+				item.Event = models.ItineraryEventStay
+				itinerary.Unscheduled = append(itinerary.Unscheduled, item)
+
+				continue
+			}
+
+			checkIn, checkOut := truncateDay(*booking.CheckIn), truncateDay(*booking.CheckOut)
+			for night := checkIn; night.Before(checkOut); night = night.AddDate(0, 0, 1) {
+				item.Event = models.ItineraryEventStay
+				if night.Equal(checkIn) {
+					item.Event = models.ItineraryEventCheckIn
+				}
+
+				place(night, item)
+			}
+
+			item.Event = models.ItineraryEventCheckOut
+			place(checkOut, item)
+		case models.BookingTypeActivity:
+			item.Event = models.ItineraryEventActivity
+			item.Activity = catalogue.activities[booking.ReferenceID]
+
+			if item.Activity == nil {
+				itinerary.Unscheduled = append(itinerary.Unscheduled, item)
+				continue
+			}
+
+			ends := item.Activity.AvailableDate.Add(time.Duration(item.Activity.DurationHours * float64(time.Hour)))
+			item.Starts, item.Ends = &item.Activity.AvailableDate, &ends
+
+			place(truncateDay(item.Activity.AvailableDate), item)
+		}
+	}
+
+	sleeping := sleepingNights(bookings, catalogue)
+
+	for i := range itinerary.Days {
+		day := &itinerary.Days[i]
+
+		sort.SliceStable(day.Items, func(a, b int) bool { return itineraryBefore(day.Items[a], day.Items[b]) })
+
+		// The trip ends on its last day, so that day has no night.
+		if i < len(itinerary.Days)-1 && !sleeping[day.Date] {
+			day.HotelMissing = true
+			itinerary.NightsWithoutHotel++
+		}
+	}
+
+	return itinerary, nil
+}
+
+// sleepingNights returns the nights, by the day they start, that the
+// bookings spend in a hotel or on a plane that lands on a later day.
+func sleepingNights(bookings []models.Booking, catalogue *itineraryCatalogue) map[time.Time]bool {
+	sleeping := map[time.Time]bool{}
+
+	cover := func(from, to time.Time) {
+		for night := truncateDay(from); night.Before(truncateDay(to)); night = night.AddDate(0, 0, 1) {
+			sleeping[night] = true
+		}
+	}
+
+	for _, booking := range bookings {
+		switch booking.Type {
+		case models.BookingTypeFlight:
+			if flight := catalogue.flights[booking.ReferenceID]; flight != nil {
+				cover(flight.DepartureTime, flight.ArrivalTime)
+			}
+		case models.BookingTypeHotel:
+			if booking.CheckIn != nil && booking.CheckOut != nil {
+				cover(*booking.CheckIn, *booking.CheckOut)
+			}
+		}
+	}
+
+	return sleeping
+}
+
+// loadItineraryCatalogue fetches every catalogue item the bookings refer to.
+func (s *TravelPlannerServiceImpl) loadItineraryCatalogue(ctx context.Context, bookings []models.Booking) (*itineraryCatalogue, error) {
+	var hotelIDs, roomTypeIDs, flightIDs, activityIDs []string
+
+	for _, booking := range bookings {
+		switch booking.Type {
+		case models.BookingTypeHotel:
+			hotelIDs = append(hotelIDs, booking.ReferenceID)
+			if booking.RoomTypeID != nil {
+				roomTypeIDs = append(roomTypeIDs, *booking.RoomTypeID)
+			}
+		case models.BookingTypeFlight:
+			flightIDs = append(flightIDs, booking.ReferenceID)
+		case models.BookingTypeActivity:
+			activityIDs = append(activityIDs, booking.ReferenceID)
+		}
+	}
+
+	hotels, err := s.repo.GetHotelsByIDs(ctx, hotelIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	roomTypes, err := s.repo.GetRoomTypesByIDs(ctx, roomTypeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	flights, err := s.repo.GetFlightsByIDs(ctx, flightIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	activities, err := s.repo.GetActivitiesByIDs(ctx, activityIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	catalogue := &itineraryCatalogue{
+		hotels:     make(map[string]*models.Hotel, len(hotels)),
+		roomTypes:  make(map[string]*models.RoomType, len(roomTypes)),
+		flights:    make(map[string]*models.Flight, len(flights)),
+		activities: make(map[string]*models.Activity, len(activities)),
+	}
+
+	for i := range hotels {
+		catalogue.hotels[hotels[i].ID] = &hotels[i]
+	}
+
+	for i := range roomTypes {
+		catalogue.roomTypes[roomTypes[i].ID] = &roomTypes[i]
+	}
+
+	for i := range flights {
+		catalogue.flights[flights[i].ID] = &flights[i]
+	}
+
+	for i := range activities {
+		catalogue.activities[activities[i].ID] = &activities[i]
+	}
+
+	return catalogue, nil
+}
+
+// itineraryRanks orders a day's items: hotels have dates but no times, so
+// check-outs go first, timed flights and activities follow in time order,
+// then check-ins and, last, stays continuing overnight.
+var itineraryRanks = map[models.ItineraryEvent]int{
+	models.ItineraryEventCheckOut: 0,
+	models.ItineraryEventFlight:   1,
+	models.ItineraryEventActivity: 1,
+	models.ItineraryEventCheckIn:  2,
+	models.ItineraryEventStay:     3,
+}
+
+// itineraryBefore reports whether item a comes before item b on their day.
+// Items that tie are ordered by when they were booked.
+func itineraryBefore(a, b models.ItineraryItem) bool {
+	if ra, rb := itineraryRanks[a.Event], itineraryRanks[b.Event]; ra != rb {
+		return ra < rb
+	}
+
+	if a.Starts != nil && b.Starts != nil && !a.Starts.Equal(*b.Starts) {
+		return a.Starts.Before(*b.Starts)
+	}
+
+	return a.Booking.CreatedAt.Before(b.Booking.CreatedAt)
+}
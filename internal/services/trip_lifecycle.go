@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// ErrInvalidTripTransition is returned when a trip cannot move from its
+// current status to the requested one.
+var ErrInvalidTripTransition = &Error{
+	Code:    models.ErrorCodeInvalidTripTransition,
+	Status:  http.StatusConflict,
+	Message: "services: invalid trip status transition",
+	Fields:  []models.FieldError{{Field: "status", Message: "invalid trip status transition"}},
+}
+
+// ErrTripNotBookable is returned when booking into a trip that is already
+// completed or cancelled.
+var ErrTripNotBookable = &Error{
+	Code:    models.ErrorCodeTripNotBookable,
+	Status:  http.StatusConflict,
+	Message: "services: trip no longer accepts bookings",
+}
+
+// tripTransitions lists, for every status, the statuses it may move to.
+// Completed and cancelled are terminal.
+var tripTransitions = map[models.TripStatus][]models.TripStatus{
+	models.TripStatusPlanning:  {models.TripStatusConfirmed, models.TripStatusCancelled},
+	models.TripStatusConfirmed: {models.TripStatusCompleted, models.TripStatusCancelled},
+	models.TripStatusCompleted: {},
+	models.TripStatusCancelled: {},
+}
+
+// Reasons recorded on bookings cancelled because of their trip.
+const (
+	tripCancelledReason = "trip cancelled"
+	tripDeletedReason   = "trip deleted"
+)
+
+// canTransitionTrip reports whether a trip may move from `from` to `to`.
+func canTransitionTrip(from, to models.TripStatus) bool {
+	for _, allowed := range tripTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isTripOpen reports whether a trip in this status still accepts bookings.
+func isTripOpen(status models.TripStatus) bool {
+	return status == models.TripStatusPlanning || status == models.TripStatusConfirmed
+}
+
+// CompleteEndedTrips marks every confirmed trip whose end date has passed as
+// completed. It is run periodically by the server's background job. Trips
+// still in planning are left alone: they were never confirmed.
+func (s *TravelPlannerServiceImpl) CompleteEndedTrips(ctx context.Context) (int64, error) {
+	var completed []string
+
+	err := s.withTx(ctx, func(tx *TravelPlannerServiceImpl) error {
+		var err error
+		if completed, err = tx.repo.CompleteEndedTrips(ctx, time.Now().UTC()); err != nil {
+			return err
+		}
+
+		for _, id := range completed {
+			if err := tx.auditTrip(ctx, systemActor, models.AuditActionStatusChange, id,
+				tripStatusField{models.TripStatusConfirmed}, tripStatusField{models.TripStatusCompleted}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("services: complete ended trips failed: %w", err)
+	}
+
+	return int64(len(completed)), nil
+}
+
+// tripStatusField is the audited view of a trip whose status alone changed.
+type tripStatusField struct {
+	Status models.TripStatus `json:"status"`
+}
+
+// cancelTripBookings cancels every booking of the trip that is not already
+// cancelled, returning its inventory, on behalf of actor. s must be bound to
+// a transaction via withTx so the trip and its bookings are cancelled together.
+func (s *TravelPlannerServiceImpl) cancelTripBookings(ctx context.Context, actor models.Actor, tripID, reason string) error {
+	bookings, err := s.repo.GetActiveBookingsByTripID(ctx, tripID)
+	if err != nil {
+		return err
+	}
+
+	for _, booking := range bookings {
+		cancelled, err := s.repo.TransitionBookingStatus(ctx, booking.ID, booking.Status, models.BookingStatusCancelled, reason)
+		if err != nil {
+			return fmt.Errorf("services: cancel booking %q: %w", booking.ID, err)
+		}
+
+		if err := s.auditBooking(ctx, actor, models.AuditActionStatusChange, cancelled, &booking, cancelled); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
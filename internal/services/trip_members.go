@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"github.com/namkatcedrickjumtock/travel-planner/persistence"
+	"gorm.io/gorm"
+)
+
+// ErrAlreadyMember is returned when inviting or adding a user who is already
+// a member of the trip.
+var ErrAlreadyMember = &Error{
+	Code:    models.ErrorCodeAlreadyMember,
+	Status:  http.StatusConflict,
+	Message: "services: user is already a member of the trip",
+	Fields:  []models.FieldError{{Field: "user_id", Message: "is already a member of the trip"}},
+}
+
+// ErrInvitationClosed is returned when accepting or revoking an invitation
+// that was already accepted or revoked.
+var ErrInvitationClosed = &Error{
+	Code:    models.ErrorCodeInvitationClosed,
+	Status:  http.StatusConflict,
+	Message: "services: invitation is no longer pending",
+}
+
+// TripMemberService defines business operations for sharing trips.
+type TripMemberService interface {
+	// ListTripMembers returns the members of a trip actor can see.
+	ListTripMembers(ctx context.Context, actor models.Actor, tripID string) ([]models.TripMember, error)
+
+	// RemoveTripMember removes userID from a trip. Owners may remove anyone
+	// but the primary owner; any member may remove themselves.
+	RemoveTripMember(ctx context.Context, actor models.Actor, tripID, userID string) error
+
+	// InviteTripMember invites a user to a trip actor is an owner of.
+	InviteTripMember(ctx context.Context, actor models.Actor, tripID string, req models.CreateInvitationRequest) (*models.TripInvitation, error)
+
+	// ListTripInvitations returns every invitation to a trip actor is an
+	// owner of.
+	ListTripInvitations(ctx context.Context, actor models.Actor, tripID string) ([]models.TripInvitation, error)
+
+	// RevokeTripInvitation withdraws a pending invitation to a trip actor is
+	// an owner of.
+	RevokeTripInvitation(ctx context.Context, actor models.Actor, tripID, invitationID string) (*models.TripInvitation, error)
+
+	// AcceptTripInvitation makes actor, the invitee, a member of the trip.
+	AcceptTripInvitation(ctx context.Context, actor models.Actor, tripID, invitationID string) (*models.TripMember, error)
+
+	// ListMyInvitations returns the invitations waiting for actor's answer.
+	ListMyInvitations(ctx context.Context, actor models.Actor) ([]models.TripInvitation, error)
+}
+
+// ListTripMembers returns a trip's members, its primary owner first.
+func (s *TravelPlannerServiceImpl) ListTripMembers(ctx context.Context, actor models.Actor, tripID string) ([]models.TripMember, error) {
+	if tripID == "" {
+		return nil, validationError("trip id must not be empty", "id")
+	}
+
+	if _, err := s.getOwnedTrip(ctx, actor, tripID, models.TripRoleViewer); err != nil {
+		return nil, fmt.Errorf("services: trip not found: %w", err)
+	}
+
+	members, err := s.repo.GetTripMembers(ctx, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("services: list trip members failed: %w", err)
+	}
+
+	return members, nil
+}
+
+// RemoveTripMember deletes a membership. The primary owner, Trip.UserID,
+// cannot be removed, since the trip would be left without its owner.
+func (s *TravelPlannerServiceImpl) RemoveTripMember(ctx context.Context, actor models.Actor, tripID, userID string) error {
+	if tripID == "" || userID == "" {
+		return validationError("trip id and user id must not be empty", "id", "user_id")
+	}
+
+	// Leaving a trip only takes being on it; removing others takes ownership.
+	role := models.TripRoleOwner
+	if userID == actor.UserID {
+		role = models.TripRoleViewer
+	}
+
+	trip, err := s.getOwnedTrip(ctx, actor, tripID, role)
+	if err != nil {
+		return fmt.Errorf("services: remove trip member failed: %w", err)
+	}
+
+	if userID == trip.UserID {
+		return fmt.Errorf("%w: the trip's primary owner cannot be removed", ErrForbidden)
+	}
+
+	if err := s.repo.RemoveTripMember(ctx, tripID, userID); err != nil {
+		return fmt.Errorf("services: remove trip member failed: %w", err)
+	}
+
+	return nil
+}
+
+// InviteTripMember creates a pending invitation for req.UserID.
+func (s *TravelPlannerServiceImpl) InviteTripMember(ctx context.Context, actor models.Actor, tripID string, req models.CreateInvitationRequest) (*models.TripInvitation, error) {
+	if tripID == "" {
+		return nil, validationError("trip id must not be empty", "id")
+	}
+
+	if !req.Role.Valid() {
+		return nil, validationError(fmt.Sprintf("role must be owner, editor or viewer, got %q", req.Role), "role")
+	}
+
+	trip, err := s.getOwnedTrip(ctx, actor, tripID, models.TripRoleOwner)
+	if err != nil {
+		return nil, fmt.Errorf("services: invite trip member failed: %w", err)
+	}
+
+	if req.UserID == trip.UserID {
+		return nil, fmt.Errorf("%w: user %q owns the trip", ErrAlreadyMember, req.UserID)
+	}
+
+	if _, err := s.repo.GetTripMember(ctx, tripID, req.UserID); err == nil {
+		return nil, fmt.Errorf("%w: user %q", ErrAlreadyMember, req.UserID)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("services: invite trip member failed: %w", err)
+	}
+
+	invitation, err := s.repo.CreateInvitation(ctx, models.TripInvitation{
+		TripID:    tripID,
+		InviteeID: req.UserID,
+		Role:      req.Role,
+		Status:    models.InvitationStatusPending,
+		InvitedBy: actor.UserID,
+	})
+	if err != nil {
+		// The partial unique index allows one pending invitation per user.
+		return nil, fmt.Errorf("services: invite trip member failed: %w", err)
+	}
+
+	return invitation, nil
+}
+
+// ListTripInvitations returns a trip's invitations, newest first.
+func (s *TravelPlannerServiceImpl) ListTripInvitations(ctx context.Context, actor models.Actor, tripID string) ([]models.TripInvitation, error) {
+	if tripID == "" {
+		return nil, validationError("trip id must not be empty", "id")
+	}
+
+	if _, err := s.getOwnedTrip(ctx, actor, tripID, models.TripRoleOwner); err != nil {
+		return nil, fmt.Errorf("services: trip not found: %w", err)
+	}
+
+	invitations, err := s.repo.GetTripInvitations(ctx, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("services: list trip invitations failed: %w", err)
+	}
+
+	return invitations, nil
+}
+
+// RevokeTripInvitation moves a pending invitation to revoked.
+func (s *TravelPlannerServiceImpl) RevokeTripInvitation(ctx context.Context, actor models.Actor, tripID, invitationID string) (*models.TripInvitation, error) {
+	if tripID == "" || invitationID == "" {
+		return nil, validationError("trip id and invitation id must not be empty", "id", "invitation_id")
+	}
+
+	if _, err := s.getOwnedTrip(ctx, actor, tripID, models.TripRoleOwner); err != nil {
+		return nil, fmt.Errorf("services: revoke invitation failed: %w", err)
+	}
+
+	var revoked *models.TripInvitation
+
+	err := s.withTx(ctx, func(tx *TravelPlannerServiceImpl) error {
+		if _, err := tx.getPendingInvitation(ctx, tripID, invitationID); err != nil {
+			return err
+		}
+
+		var err error
+		revoked, err = tx.setInvitationStatus(ctx, invitationID, models.InvitationStatusRevoked)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("services: revoke invitation failed: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// AcceptTripInvitation adds the invitee as a member with the invited role
+// and closes the invitation, atomically. Invitations addressed to someone
+// else are reported as missing.
+func (s *TravelPlannerServiceImpl) AcceptTripInvitation(ctx context.Context, actor models.Actor, tripID, invitationID string) (*models.TripMember, error) {
+	if tripID == "" || invitationID == "" {
+		return nil, validationError("trip id and invitation id must not be empty", "id", "invitation_id")
+	}
+
+	var member *models.TripMember
+
+	err := s.withTx(ctx, func(tx *TravelPlannerServiceImpl) error {
+		invitation, err := tx.getPendingInvitation(ctx, tripID, invitationID)
+		if err != nil {
+			return err
+		}
+
+		if invitation.InviteeID != actor.UserID {
+			return fmt.Errorf("services: invitation %q is not addressed to the caller: %w", invitationID, gorm.ErrRecordNotFound)
+		}
+
+		// A deleted trip cannot be joined.
+		if _, err := tx.repo.GetTripByID(ctx, tripID); err != nil {
+			return err
+		}
+
+		member, err = tx.repo.AddTripMember(ctx, models.TripMember{
+			TripID: tripID,
+			UserID: actor.UserID,
+			Role:   invitation.Role,
+		})
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return fmt.Errorf("%w: user %q", ErrAlreadyMember, actor.UserID)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.setInvitationStatus(ctx, invitationID, models.InvitationStatusAccepted)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("services: accept invitation failed: %w", err)
+	}
+
+	return member, nil
+}
+
+// ListMyInvitations returns actor's pending invitations, newest first.
+func (s *TravelPlannerServiceImpl) ListMyInvitations(ctx context.Context, actor models.Actor) ([]models.TripInvitation, error) {
+	invitations, err := s.repo.GetPendingInvitations(ctx, actor.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("services: list invitations failed: %w", err)
+	}
+
+	return invitations, nil
+}
+
+// getPendingInvitation locks an invitation to tripID and checks that it is
+// still pending. s must be bound to a transaction via withTx.
+func (s *TravelPlannerServiceImpl) getPendingInvitation(ctx context.Context, tripID, invitationID string) (*models.TripInvitation, error) {
+	invitation, err := s.repo.GetInvitationByIDForUpdate(ctx, invitationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if invitation.TripID != tripID {
+		return nil, fmt.Errorf("services: invitation %q is not for trip %q: %w", invitationID, tripID, gorm.ErrRecordNotFound)
+	}
+
+	if invitation.Status != models.InvitationStatusPending {
+		return nil, fmt.Errorf("%w: invitation was %s", ErrInvitationClosed, invitation.Status)
+	}
+
+	return invitation, nil
+}
+
+// setInvitationStatus closes a pending invitation.
+func (s *TravelPlannerServiceImpl) setInvitationStatus(ctx context.Context, invitationID string, to models.InvitationStatus) (*models.TripInvitation, error) {
+	invitation, err := s.repo.SetInvitationStatus(ctx, invitationID, models.InvitationStatusPending, to)
+	if errors.Is(err, persistence.ErrInvitationStatusChanged) {
+		return nil, fmt.Errorf("%w: invitation was answered by another request", ErrInvitationClosed)
+	}
+
+	return invitation, err
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// ErrRestoreWindowExpired is returned when restoring a trip that was deleted
+// longer ago than the retention window allows.
+var ErrRestoreWindowExpired = &Error{
+	Code:    models.ErrorCodeRestoreWindowExpired,
+	Status:  http.StatusGone,
+	Message: "services: restore window has expired",
+}
+
+// RestoreTrip checks ownership and the retention window, then undeletes the
+// trip. Bookings cancelled by the deletion stay cancelled.
+func (s *TravelPlannerServiceImpl) RestoreTrip(ctx context.Context, actor models.Actor, id string) (*models.Trip, error) {
+	if id == "" {
+		return nil, validationError("trip id must not be empty", "id")
+	}
+
+	var restored *models.Trip
+
+	err := s.withTx(ctx, func(tx *TravelPlannerServiceImpl) error {
+		trip, err := tx.repo.GetDeletedTripByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.authorizeTrip(ctx, actor, trip, models.TripRoleOwner); err != nil {
+			return err
+		}
+
+		if deletedFor := time.Since(trip.DeletedAt.Time); deletedFor > s.cfg.DeletedRetention {
+			return fmt.Errorf("%w: trip was deleted %s ago, the window is %s",
+				ErrRestoreWindowExpired, deletedFor.Round(time.Minute), s.cfg.DeletedRetention)
+		}
+
+		if restored, err = tx.repo.RestoreTrip(ctx, id); err != nil {
+			return err
+		}
+
+		return tx.auditTrip(ctx, actor, models.AuditActionRestore, id, nil, restored)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("services: restore trip failed: %w", err)
+	}
+
+	return restored, nil
+}
+
+// PurgeDeletedTrips removes trips deleted longer ago than the retention
+// window. It is run periodically by the server's background job. The audit
+// log keeps the purged trips' history.
+func (s *TravelPlannerServiceImpl) PurgeDeletedTrips(ctx context.Context) (int64, error) {
+	var purged []string
+
+	err := s.withTx(ctx, func(tx *TravelPlannerServiceImpl) error {
+		var err error
+		if purged, err = tx.repo.PurgeDeletedTrips(ctx, time.Now().UTC().Add(-s.cfg.DeletedRetention)); err != nil {
+			return err
+		}
+
+		for _, id := range purged {
+			if err := tx.auditTrip(ctx, systemActor, models.AuditActionPurge, id, nil, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("services: purge deleted trips failed: %w", err)
+	}
+
+	return int64(len(purged)), nil
+}
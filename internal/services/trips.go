@@ -1,55 +1,127 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"github.com/namkatcedrickjumtock/travel-planner/persistence"
+	"gorm.io/gorm"
 )
 
 // TripService defines all business operations related to trip management.
 type TripService interface {
-	// CreateTrip validates the request and persists a new trip.
-	CreateTrip(req models.CreateTripRequest) (*models.Trip, error)
-
-	// GetTrip retrieves a single trip by ID.
-	GetTrip(id string) (*models.Trip, error)
+	// CreateTrip validates the request and persists a new trip owned by actor,
+	// or by one of actor's clients when req.ClientID is set.
+	CreateTrip(ctx context.Context, actor models.Actor, req models.CreateTripRequest) (*models.Trip, error)
+
+	// GetTrip retrieves a single trip by ID, if actor is at least a viewer.
+	GetTrip(ctx context.Context, actor models.Actor, id string) (*models.Trip, error)
+
+	// ListTrips returns all trips actor may see: their own, those shared
+	// with them and, for agents, their clients'.
+	ListTrips(ctx context.Context, actor models.Actor, page models.PageParams) (*models.Page[models.Trip], error)
+
+	// UpdateTrip applies partial updates to a trip actor is an editor of,
+	// provided it is still at `version` (or version is AnyVersion).
+	UpdateTrip(ctx context.Context, actor models.Actor, id string, version int, req models.UpdateTripRequest) (*models.Trip, error)
+
+	// DeleteTrip soft-deletes a trip actor is an owner of together with its
+	// bookings, cancelling any that are still active, provided it is still
+	// at `version` (or version is AnyVersion).
+	DeleteTrip(ctx context.Context, actor models.Actor, id string, version int) error
+
+	// RestoreTrip brings back a trip actor is an owner of within the
+	// retention window, along with the bookings deleted with it. Bookings
+	// that DeleteTrip cancelled stay cancelled.
+	RestoreTrip(ctx context.Context, actor models.Actor, id string) (*models.Trip, error)
+
+	// PurgeDeletedTrips permanently removes trips whose retention window has
+	// expired and returns how many were removed.
+	PurgeDeletedTrips(ctx context.Context) (int64, error)
+
+	// SearchTrips returns the trips actor may see that match the filters.
+	SearchTrips(ctx context.Context, actor models.Actor, params models.TripSearchParams, page models.PageParams) (*models.Page[models.Trip], error)
+
+	// GetTripSummary totals a trip's bookings and compares them with its
+	// budget. An empty currency reports in the budget currency.
+	GetTripSummary(ctx context.Context, actor models.Actor, id, currency string) (*models.TripSummary, error)
+
+	// GetTripItinerary lays the bookings of a trip actor can see out day by
+	// day, with the booked flights, hotels and activities resolved.
+	GetTripItinerary(ctx context.Context, actor models.Actor, id string) (*models.Itinerary, error)
+
+	// GetTripConflicts checks the bookings of a trip actor can see for
+	// overlaps, impossible connections, accommodation gaps and bookings
+	// outside the trip.
+	GetTripConflicts(ctx context.Context, actor models.Actor, id string) (*models.TripConflicts, error)
+
+	// CompleteEndedTrips moves confirmed trips whose end date has passed to
+	// completed and returns how many were moved.
+	CompleteEndedTrips(ctx context.Context) (int64, error)
+}
 
-	// ListTrips returns all trips in the system.
-	ListTrips() ([]models.Trip, error)
+// CreateTrip validates the input then delegates to the repository.
+// The trip is owned by the calling actor unless an agent creates it for a client.
+func (s *TravelPlannerServiceImpl) CreateTrip(ctx context.Context, actor models.Actor, req models.CreateTripRequest) (*models.Trip, error) {
+	ownerID := actor.UserID
 
-	// UpdateTrip applies partial updates to an existing trip.
-	UpdateTrip(id string, req models.UpdateTripRequest) (*models.Trip, error)
+	if req.ClientID != nil && *req.ClientID != actor.UserID {
+		allowed, err := s.canActFor(ctx, actor, *req.ClientID)
+		if err != nil {
+			return nil, fmt.Errorf("services: create trip failed: %w", err)
+		}
 
-	// DeleteTrip removes a trip and all its associated bookings (via DB cascade).
-	DeleteTrip(id string) error
+		if !allowed {
+			return nil, fmt.Errorf("%w: cannot create trips for user %q", ErrForbidden, *req.ClientID)
+		}
 
-	// SearchTrips returns trips matching the provided filter parameters.
-	SearchTrips(params models.TripSearchParams) ([]models.Trip, error)
-}
+		ownerID = *req.ClientID
+	}
 
-// CreateTrip validates the input then delegates to the repository.
-func (s *TravelPlannerServiceImpl) CreateTrip(req models.CreateTripRequest) (*models.Trip, error) {
 	// Business rule: end date must be after start date.
 	if !req.EndDate.After(req.StartDate) {
-		return nil, fmt.Errorf("services: end_date must be after start_date")
+		return nil, validationError("end_date must be after start_date", "end_date")
 	}
 
 	// Business rule: trips cannot be created in the past.
 	if req.StartDate.Before(time.Now().UTC().Truncate(24 * time.Hour)) {
-		return nil, fmt.Errorf("services: start_date cannot be in the past")
+		return nil, validationError("start_date cannot be in the past", "start_date")
+	}
+
+	// Business rule: a budget must be a positive amount in a valid currency.
+	if req.Budget != nil {
+		if err := validateMoney("trip budget", "budget", *req.Budget); err != nil {
+			return nil, err
+		}
+	} else if req.EnforceBudget {
+		return nil, validationError("enforce_budget requires a budget", "enforce_budget")
 	}
 
 	trip := models.Trip{
-		UserID:      req.UserID,
-		Title:       req.Title,
-		Destination: req.Destination,
-		StartDate:   req.StartDate,
-		EndDate:     req.EndDate,
-		Status:      models.TripStatusPlanning,
+		UserID:           ownerID,
+		Title:            req.Title,
+		Destination:      req.Destination,
+		StartDate:        req.StartDate,
+		EndDate:          req.EndDate,
+		Status:           models.TripStatusPlanning,
+		Budget:           req.Budget,
+		EnforceBudget:    req.EnforceBudget,
+		EnforceConflicts: req.EnforceConflicts,
 	}
 
-	created, err := s.repo.CreateTrip(trip)
+	var created *models.Trip
+
+	err := s.withTx(ctx, func(tx *TravelPlannerServiceImpl) error {
+		var err error
+		if created, err = tx.repo.CreateTrip(ctx, trip); err != nil {
+			return err
+		}
+
+		return tx.auditTrip(ctx, actor, models.AuditActionCreate, created.ID, nil, created)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("services: create trip failed: %w", err)
 	}
@@ -58,12 +130,12 @@ func (s *TravelPlannerServiceImpl) CreateTrip(req models.CreateTripRequest) (*mo
 }
 
 // GetTrip retrieves a trip by its UUID.
-func (s *TravelPlannerServiceImpl) GetTrip(id string) (*models.Trip, error) {
+func (s *TravelPlannerServiceImpl) GetTrip(ctx context.Context, actor models.Actor, id string) (*models.Trip, error) {
 	if id == "" {
-		return nil, fmt.Errorf("services: trip id must not be empty")
+		return nil, validationError("trip id must not be empty", "id")
 	}
 
-	trip, err := s.repo.GetTripByID(id)
+	trip, err := s.getOwnedTrip(ctx, actor, id, models.TripRoleViewer)
 	if err != nil {
 		return nil, fmt.Errorf("services: get trip failed: %w", err)
 	}
@@ -71,9 +143,15 @@ func (s *TravelPlannerServiceImpl) GetTrip(id string) (*models.Trip, error) {
 	return trip, nil
 }
 
-// ListTrips returns all trips ordered by creation time descending.
-func (s *TravelPlannerServiceImpl) ListTrips() ([]models.Trip, error) {
-	trips, err := s.repo.GetAllTrips()
+// ListTrips returns the trips of the actor and, for agents, of their clients,
+// together with the trips shared with the actor, one page at a time.
+func (s *TravelPlannerServiceImpl) ListTrips(ctx context.Context, actor models.Actor, page models.PageParams) (*models.Page[models.Trip], error) {
+	userIDs, err := s.visibleUserIDs(ctx, actor)
+	if err != nil {
+		return nil, fmt.Errorf("services: list trips failed: %w", err)
+	}
+
+	trips, err := s.repo.GetTripsByUserIDs(ctx, userIDs, actor.UserID, page)
 	if err != nil {
 		return nil, fmt.Errorf("services: list trips failed: %w", err)
 	}
@@ -83,9 +161,38 @@ func (s *TravelPlannerServiceImpl) ListTrips() ([]models.Trip, error) {
 
 // UpdateTrip builds an update map from the non-nil fields in the request
 // and applies it to the trip with the given ID.
-func (s *TravelPlannerServiceImpl) UpdateTrip(id string, req models.UpdateTripRequest) (*models.Trip, error) {
+func (s *TravelPlannerServiceImpl) UpdateTrip(ctx context.Context, actor models.Actor, id string, version int, req models.UpdateTripRequest) (*models.Trip, error) {
 	if id == "" {
-		return nil, fmt.Errorf("services: trip id must not be empty")
+		return nil, validationError("trip id must not be empty", "id")
+	}
+
+	var updated *models.Trip
+
+	// The ownership check and the write share a transaction with the trip
+	// row locked, so a concurrent delete or reassignment cannot slip between them.
+	err := s.withTx(ctx, func(tx *TravelPlannerServiceImpl) error {
+		var err error
+		updated, err = tx.updateTrip(ctx, actor, id, version, req)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("services: update trip failed: %w", err)
+	}
+
+	return updated, nil
+}
+
+// updateTrip is the transactional body of UpdateTrip; s must be bound to a
+// transaction via withTx.
+func (s *TravelPlannerServiceImpl) updateTrip(ctx context.Context, actor models.Actor, id string, version int, req models.UpdateTripRequest) (*models.Trip, error) {
+	trip, err := s.getOwnedTripForUpdate(ctx, actor, id, models.TripRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkVersion("trip", id, trip.Version, version); err != nil {
+		return nil, err
 	}
 
 	updates := make(map[string]interface{})
@@ -106,46 +213,225 @@ func (s *TravelPlannerServiceImpl) UpdateTrip(id string, req models.UpdateTripRe
 		updates["end_date"] = *req.EndDate
 	}
 
-	if req.Status != nil {
+	// Business rule: status follows the trip state machine. Re-sending the
+	// current status is not a transition and is ignored.
+	action, cancelling := models.AuditActionUpdate, false
+
+	if req.Status != nil && *req.Status != trip.Status {
+		if !canTransitionTrip(trip.Status, *req.Status) {
+			return nil, fmt.Errorf("%w: cannot move trip from %s to %q", ErrInvalidTripTransition, trip.Status, *req.Status)
+		}
+
 		updates["status"] = *req.Status
+		action, cancelling = models.AuditActionStatusChange, *req.Status == models.TripStatusCancelled
+	}
+
+	if req.Budget != nil {
+		if err := validateMoney("trip budget", "budget", *req.Budget); err != nil {
+			return nil, err
+		}
+
+		updates["budget_amount"] = req.Budget.Amount
+		updates["budget_currency"] = req.Budget.Currency
+	}
+
+	if req.EnforceBudget != nil {
+		if *req.EnforceBudget && trip.Budget == nil && req.Budget == nil {
+			return nil, validationError("enforce_budget requires a budget", "enforce_budget")
+		}
+
+		updates["enforce_budget"] = *req.EnforceBudget
+	}
+
+	if req.EnforceConflicts != nil {
+		updates["enforce_conflicts"] = *req.EnforceConflicts
 	}
 
 	if len(updates) == 0 {
 		// Nothing to update — return the current record as-is.
-		return s.repo.GetTripByID(id)
+		return trip, nil
 	}
 
 	// Always refresh updated_at when any field changes.
 	updates["updated_at"] = time.Now().UTC()
 
-	updated, err := s.repo.UpdateTrip(id, updates)
+	updated, err := s.repo.UpdateTrip(ctx, id, trip.Version, updates)
 	if err != nil {
-		return nil, fmt.Errorf("services: update trip failed: %w", err)
+		if errors.Is(err, persistence.ErrTripVersionChanged) {
+			return nil, fmt.Errorf("%w: %v", ErrVersionMismatch, err)
+		}
+
+		return nil, err
+	}
+
+	if err := s.auditTrip(ctx, actor, action, id, trip, updated); err != nil {
+		return nil, err
+	}
+
+	// Cancelling a trip cancels its bookings in the same transaction.
+	if cancelling {
+		if err := s.cancelTripBookings(ctx, actor, id, tripCancelledReason); err != nil {
+			return nil, err
+		}
 	}
 
 	return updated, nil
 }
 
-// DeleteTrip removes the trip with the given ID.
-// Associated bookings are deleted automatically by the DB cascade constraint.
-func (s *TravelPlannerServiceImpl) DeleteTrip(id string) error {
+// DeleteTrip soft-deletes the trip with the given ID and, in the same
+// transaction, its bookings, after cancelling those of an open trip. Nothing
+// is removed by the database: bookings.trip_id is ON DELETE RESTRICT, and
+// rows only go for good when PurgeDeletedTrips runs.
+func (s *TravelPlannerServiceImpl) DeleteTrip(ctx context.Context, actor models.Actor, id string, version int) error {
 	if id == "" {
-		return fmt.Errorf("services: trip id must not be empty")
+		return validationError("trip id must not be empty", "id")
 	}
 
-	if err := s.repo.DeleteTrip(id); err != nil {
+	err := s.withTx(ctx, func(tx *TravelPlannerServiceImpl) error {
+		trip, err := tx.getOwnedTripForUpdate(ctx, actor, id, models.TripRoleOwner)
+		if err != nil {
+			return err
+		}
+
+		if err := checkVersion("trip", id, trip.Version, version); err != nil {
+			return err
+		}
+
+		// A deleted trip must not keep holding seats and rooms. Bookings of
+		// finished trips are history and are left as they were.
+		if isTripOpen(trip.Status) {
+			if err := tx.cancelTripBookings(ctx, actor, id, tripDeletedReason); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.repo.DeleteTrip(ctx, id); err != nil {
+			return err
+		}
+
+		return tx.auditTrip(ctx, actor, models.AuditActionDelete, id, trip, nil)
+	})
+	if err != nil {
 		return fmt.Errorf("services: delete trip failed: %w", err)
 	}
 
 	return nil
 }
 
-// SearchTrips delegates to the repository with the provided filter params.
-func (s *TravelPlannerServiceImpl) SearchTrips(params models.TripSearchParams) ([]models.Trip, error) {
-	trips, err := s.repo.SearchTrips(params)
+// SearchTrips delegates to the repository with the provided filter params,
+// always scoped to the trips the actor may manage.
+func (s *TravelPlannerServiceImpl) SearchTrips(ctx context.Context, actor models.Actor, params models.TripSearchParams, page models.PageParams) (*models.Page[models.Trip], error) {
+	userIDs, err := s.visibleUserIDs(ctx, actor)
+	if err != nil {
+		return nil, fmt.Errorf("services: search trips failed: %w", err)
+	}
+
+	params.UserIDs = userIDs
+	params.MemberID = actor.UserID
+
+	trips, err := s.repo.SearchTrips(ctx, params, page)
 	if err != nil {
 		return nil, fmt.Errorf("services: search trips failed: %w", err)
 	}
 
 	return trips, nil
-}
\ No newline at end of file
+}
+
+// getOwnedTrip loads a trip and checks that actor holds at least `role` on
+// it; see authorizeTrip. A trip the actor has no access to is reported
+// exactly like a missing one, so callers cannot probe for the existence of
+// other users' trips.
+func (s *TravelPlannerServiceImpl) getOwnedTrip(ctx context.Context, actor models.Actor, id string, role models.TripRole) (*models.Trip, error) {
+	trip, err := s.repo.GetTripByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.authorizeTrip(ctx, actor, trip, role)
+}
+
+// getOwnedTripForUpdate is getOwnedTrip with the trip row locked until the
+// surrounding transaction ends. s must be bound to a transaction via withTx.
+func (s *TravelPlannerServiceImpl) getOwnedTripForUpdate(ctx context.Context, actor models.Actor, id string, role models.TripRole) (*models.Trip, error) {
+	trip, err := s.repo.GetTripByIDForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.authorizeTrip(ctx, actor, trip, role)
+}
+
+// authorizeTrip returns trip if actor holds at least `role` on it. It
+// returns a not-found error when actor has no access at all, and
+// ErrForbidden when they may see the trip but their role falls short.
+func (s *TravelPlannerServiceImpl) authorizeTrip(ctx context.Context, actor models.Actor, trip *models.Trip, role models.TripRole) (*models.Trip, error) {
+	held, err := s.tripRole(ctx, actor, trip)
+	if err != nil {
+		return nil, err
+	}
+
+	if held == "" {
+		return nil, fmt.Errorf("services: trip %q is not managed by the caller: %w", trip.ID, gorm.ErrRecordNotFound)
+	}
+
+	if !held.Allows(role) {
+		return nil, fmt.Errorf("%w: a trip %s cannot do this, it needs the %s role", ErrForbidden, held, role)
+	}
+
+	return trip, nil
+}
+
+// tripRole returns the role actor holds on trip, or "" for none. Whoever may
+// manage the owner's trips (the owner, their agents and holders of
+// PermissionAllTrips) is an owner; anyone else has their membership's role.
+func (s *TravelPlannerServiceImpl) tripRole(ctx context.Context, actor models.Actor, trip *models.Trip) (models.TripRole, error) {
+	allowed, err := s.canActFor(ctx, actor, trip.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	if allowed {
+		return models.TripRoleOwner, nil
+	}
+
+	member, err := s.repo.GetTripMember(ctx, trip.ID, actor.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return member.Role, nil
+}
+
+// canActFor reports whether actor may manage trips owned by userID.
+func (s *TravelPlannerServiceImpl) canActFor(ctx context.Context, actor models.Actor, userID string) (bool, error) {
+	if userID == actor.UserID || actor.Can(models.PermissionAllTrips) {
+		return true, nil
+	}
+
+	if !actor.Can(models.PermissionClientTrips) {
+		return false, nil
+	}
+
+	return s.repo.IsAgentClient(ctx, actor.UserID, userID)
+}
+
+// visibleUserIDs returns the owners whose trips appear in actor's listings:
+// the actor and, when they hold PermissionClientTrips, their clients.
+func (s *TravelPlannerServiceImpl) visibleUserIDs(ctx context.Context, actor models.Actor) ([]string, error) {
+	userIDs := []string{actor.UserID}
+
+	if !actor.Can(models.PermissionClientTrips) {
+		return userIDs, nil
+	}
+
+	clientIDs, err := s.repo.GetAgentClientIDs(ctx, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(userIDs, clientIDs...), nil
+}
@@ -0,0 +1,141 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"gorm.io/gorm"
+)
+
+// ActivityRepository defines database operations for activities.
+type ActivityRepository interface {
+	// CreateActivity inserts a new activity record and returns the persisted model.
+	CreateActivity(ctx context.Context, activity models.Activity) (*models.Activity, error)
+
+	// GetActivityByID fetches a single activity by its UUID primary key.
+	GetActivityByID(ctx context.Context, id string) (*models.Activity, error)
+
+	// GetActivitiesByIDs returns the activities with the given IDs.
+	// Missing IDs are omitted.
+	GetActivitiesByIDs(ctx context.Context, ids []string) ([]models.Activity, error)
+
+	// GetAllActivities returns activities, optionally filtered by location, date and price.
+	GetAllActivities(ctx context.Context, params models.ActivitySearchParams, page models.PageParams) (*models.Page[models.Activity], error)
+
+	// UpdateActivity applies a partial update map to the activity with the given ID.
+	// Only the keys present in `updates` are written to the database.
+	UpdateActivity(ctx context.Context, id string, updates map[string]interface{}) (*models.Activity, error)
+
+	// DeleteActivity hard-deletes the activity with the given ID.
+	DeleteActivity(ctx context.Context, id string) error
+}
+
+// activitySort whitelists the fields activities can be sorted by.
+// available_date is nullable, so undated activities sort first.
+var activitySort = sortSpec[models.Activity]{
+	fields: map[string]sortField[models.Activity]{
+		"available_date": {"COALESCE(available_date, '0001-01-01T00:00:00Z')", "timestamptz", func(a models.Activity) string { return timeValue(a.AvailableDate) }},
+		"price":          {"price_amount", "numeric", func(a models.Activity) string { return a.Price.Amount.String() }},
+		"name":           {"name", "varchar", func(a models.Activity) string { return a.Name }},
+		"created_at":     {"created_at", "timestamptz", func(a models.Activity) string { return timeValue(a.CreatedAt) }},
+	},
+	id: func(a models.Activity) string { return a.ID },
+}
+
+// CreateActivity inserts a new activity into the database.
+func (r *RepositoryPg) CreateActivity(ctx context.Context, activity models.Activity) (*models.Activity, error) {
+	if err := r.gormDB.WithContext(ctx).Create(&activity).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to create activity: %w", err)
+	}
+
+	return &activity, nil
+}
+
+// GetActivityByID retrieves an activity by its primary key.
+func (r *RepositoryPg) GetActivityByID(ctx context.Context, id string) (*models.Activity, error) {
+	var activity models.Activity
+
+	if err := r.gormDB.WithContext(ctx).First(&activity, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get activity with id %q: %w", id, err)
+	}
+
+	return &activity, nil
+}
+
+// GetActivitiesByIDs loads the activities in ids, ordered by ID.
+func (r *RepositoryPg) GetActivitiesByIDs(ctx context.Context, ids []string) ([]models.Activity, error) {
+	var activities []models.Activity
+
+	if len(ids) == 0 {
+		return activities, nil
+	}
+
+	if err := r.gormDB.WithContext(ctx).Where("id IN ?", ids).Order("id ASC").Find(&activities).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get activities: %w", err)
+	}
+
+	return activities, nil
+}
+
+// GetAllActivities returns a page of activities, by default ordered by
+// available_date ascending.
+// location is a case-insensitive partial match; date matches the calendar day
+// of available_date; min/max price are inclusive bounds.
+func (r *RepositoryPg) GetAllActivities(ctx context.Context, params models.ActivitySearchParams, page models.PageParams) (*models.Page[models.Activity], error) {
+	query := r.gormDB.WithContext(ctx).Model(&models.Activity{})
+
+	if params.Location != "" {
+		query = query.Where("location ILIKE ?", "%"+params.Location+"%")
+	}
+
+	if !params.Date.IsZero() {
+		query = query.Where("available_date >= ? AND available_date < ?", params.Date, params.Date.AddDate(0, 0, 1))
+	}
+
+	if params.MinPrice != nil {
+		query = query.Where("price_amount >= ?", *params.MinPrice)
+	}
+
+	if params.MaxPrice != nil {
+		query = query.Where("price_amount <= ?", *params.MaxPrice)
+	}
+
+	activities, err := paginate(query, page, activitySort, "available_date")
+	if err != nil {
+		return nil, fmt.Errorf("persistence: failed to list activities: %w", err)
+	}
+
+	return activities, nil
+}
+
+// UpdateActivity applies the provided field map to the activity row and
+// returns the updated record.
+func (r *RepositoryPg) UpdateActivity(ctx context.Context, id string, updates map[string]interface{}) (*models.Activity, error) {
+	// Confirm the activity exists before attempting to update.
+	activity, err := r.GetActivityByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("persistence: update pre-check failed: %w", err)
+	}
+
+	if err := r.gormDB.WithContext(ctx).Model(activity).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to update activity with id %q: %w", id, err)
+	}
+
+	return activity, nil
+}
+
+// DeleteActivity removes the activity row with the given ID.
+// Returns an error if no row was deleted (i.e. ID not found).
+func (r *RepositoryPg) DeleteActivity(ctx context.Context, id string) error {
+	result := r.gormDB.WithContext(ctx).Delete(&models.Activity{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("persistence: failed to delete activity with id %q: %w", id, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("persistence: no activity found with id %q: %w", id, gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
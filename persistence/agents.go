@@ -0,0 +1,78 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AgentRepository defines database operations for agent–client assignments.
+type AgentRepository interface {
+	// AddAgentClient assigns a client to an agent. Assigning twice is a no-op.
+	AddAgentClient(ctx context.Context, agentID, clientID string) (*models.AgentClient, error)
+
+	// RemoveAgentClient removes a client from an agent.
+	RemoveAgentClient(ctx context.Context, agentID, clientID string) error
+
+	// GetAgentClientIDs returns the user IDs of an agent's clients.
+	GetAgentClientIDs(ctx context.Context, agentID string) ([]string, error)
+
+	// IsAgentClient reports whether clientID is assigned to agentID.
+	IsAgentClient(ctx context.Context, agentID, clientID string) (bool, error)
+}
+
+// AddAgentClient inserts the assignment, ignoring an existing identical row.
+func (r *RepositoryPg) AddAgentClient(ctx context.Context, agentID, clientID string) (*models.AgentClient, error) {
+	assignment := models.AgentClient{AgentID: agentID, ClientID: clientID}
+
+	if err := r.gormDB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&assignment).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to assign client %q to agent %q: %w", clientID, agentID, err)
+	}
+
+	return &assignment, nil
+}
+
+// RemoveAgentClient deletes the assignment.
+// Returns an error wrapping gorm.ErrRecordNotFound if it did not exist.
+func (r *RepositoryPg) RemoveAgentClient(ctx context.Context, agentID, clientID string) error {
+	result := r.gormDB.WithContext(ctx).Delete(&models.AgentClient{}, "agent_id = ? AND client_id = ?", agentID, clientID)
+	if result.Error != nil {
+		return fmt.Errorf("persistence: failed to remove client %q from agent %q: %w", clientID, agentID, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("persistence: client %q is not assigned to agent %q: %w", clientID, agentID, gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+// GetAgentClientIDs returns client IDs ordered by assignment time.
+func (r *RepositoryPg) GetAgentClientIDs(ctx context.Context, agentID string) ([]string, error) {
+	var clientIDs []string
+
+	if err := r.gormDB.WithContext(ctx).Model(&models.AgentClient{}).
+		Where("agent_id = ?", agentID).
+		Order("created_at ASC").
+		Pluck("client_id", &clientIDs).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to list clients of agent %q: %w", agentID, err)
+	}
+
+	return clientIDs, nil
+}
+
+// IsAgentClient checks for a matching assignment row.
+func (r *RepositoryPg) IsAgentClient(ctx context.Context, agentID, clientID string) (bool, error) {
+	var count int64
+
+	if err := r.gormDB.WithContext(ctx).Model(&models.AgentClient{}).
+		Where("agent_id = ? AND client_id = ?", agentID, clientID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("persistence: failed to check client %q of agent %q: %w", clientID, agentID, err)
+	}
+
+	return count > 0, nil
+}
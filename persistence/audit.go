@@ -0,0 +1,95 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+)
+
+// AuditRepository defines database operations for the audit log. The log is
+// append-only: there is deliberately no way to change or remove an entry.
+type AuditRepository interface {
+	// AppendAudit inserts a single audit entry.
+	AppendAudit(ctx context.Context, entry models.AuditEntry) error
+
+	// GetTripHistory returns a page of the entries recorded against a trip
+	// and its bookings.
+	GetTripHistory(ctx context.Context, tripID string, page models.PageParams) (*models.Page[models.AuditEntry], error)
+
+	// SearchAudit filters the whole audit log. Any zero-value filter field
+	// is ignored.
+	SearchAudit(ctx context.Context, params models.AuditSearchParams, page models.PageParams) (*models.Page[models.AuditEntry], error)
+}
+
+// auditSort whitelists the fields audit entries can be sorted by.
+var auditSort = sortSpec[models.AuditEntry]{
+	fields: map[string]sortField[models.AuditEntry]{
+		"created_at": {"created_at", "timestamptz", func(e models.AuditEntry) string { return timeValue(e.CreatedAt) }},
+	},
+	id: func(e models.AuditEntry) string { return e.ID },
+}
+
+// AppendAudit inserts the entry. Called inside WithTx, the entry commits or
+// rolls back together with the change it records.
+func (r *RepositoryPg) AppendAudit(ctx context.Context, entry models.AuditEntry) error {
+	if err := r.gormDB.WithContext(ctx).Create(&entry).Error; err != nil {
+		return fmt.Errorf("persistence: failed to append %s %s audit entry: %w", entry.EntityType, entry.Action, err)
+	}
+
+	return nil
+}
+
+// GetTripHistory returns the trip's entries, by default newest first.
+func (r *RepositoryPg) GetTripHistory(ctx context.Context, tripID string, page models.PageParams) (*models.Page[models.AuditEntry], error) {
+	query := r.gormDB.WithContext(ctx).Model(&models.AuditEntry{}).Where("trip_id = ?", tripID)
+
+	entries, err := paginate(query, page, auditSort, "-created_at")
+	if err != nil {
+		return nil, fmt.Errorf("persistence: failed to get history of trip %q: %w", tripID, err)
+	}
+
+	return entries, nil
+}
+
+// SearchAudit applies the non-zero fields in params as WHERE filters and
+// returns one page of matches, by default newest first.
+func (r *RepositoryPg) SearchAudit(ctx context.Context, params models.AuditSearchParams, page models.PageParams) (*models.Page[models.AuditEntry], error) {
+	query := r.gormDB.WithContext(ctx).Model(&models.AuditEntry{})
+
+	if params.EntityType != "" {
+		query = query.Where("entity_type = ?", params.EntityType)
+	}
+
+	if params.EntityID != "" {
+		query = query.Where("entity_id = ?", params.EntityID)
+	}
+
+	if params.TripID != "" {
+		query = query.Where("trip_id = ?", params.TripID)
+	}
+
+	if params.ActorID != "" {
+		query = query.Where("actor_id = ?", params.ActorID)
+	}
+
+	if params.Action != "" {
+		query = query.Where("action = ?", params.Action)
+	}
+
+	if !params.From.IsZero() {
+		query = query.Where("created_at >= ?", params.From)
+	}
+
+	// To is a calendar day, so include everything up to the next midnight.
+	if !params.To.IsZero() {
+		query = query.Where("created_at < ?", params.To.AddDate(0, 0, 1))
+	}
+
+	entries, err := paginate(query, page, auditSort, "-created_at")
+	if err != nil {
+		return nil, fmt.Errorf("persistence: failed to search audit log: %w", err)
+	}
+
+	return entries, nil
+}
@@ -1,26 +1,99 @@
 package persistence
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrBookingStatusChanged is returned by TransitionBookingStatus when the
+// booking no longer has the expected status, i.e. a concurrent request
+// moved it first.
+var ErrBookingStatusChanged = errors.New("persistence: booking status changed concurrently")
+
+// ErrInsufficientSeats is returned by CreateBooking when a flight does not
+// have enough seats left to cover the booking's quantity.
+var ErrInsufficientSeats = errors.New("persistence: not enough seats available")
+
+// ErrHotelUnavailable is returned by CreateBooking when a hotel already holds
+// a live booking for at least one of the requested nights.
+var ErrHotelUnavailable = errors.New("persistence: hotel already booked for the requested nights")
+
 // BookingRepository defines database operations for bookings.
 type BookingRepository interface {
 	// CreateBooking inserts a new booking record and returns the persisted model.
-	CreateBooking(booking models.Booking) (*models.Booking, error)
+	// Flight bookings also take their seats from the flight's inventory, and
+	// hotel bookings reserve room-nights or are checked for overlapping stays.
+	// The booking's Travellers, which must already exist, are linked to it.
+	CreateBooking(ctx context.Context, booking models.Booking) (*models.Booking, error)
+
+	// GetBookingByID fetches a single booking, with its travellers, by its
+	// UUID primary key.
+	GetBookingByID(ctx context.Context, id string) (*models.Booking, error)
+
+	// GetBookingsByTripID returns a page of the bookings associated with the given trip.
+	GetBookingsByTripID(ctx context.Context, tripID string, page models.PageParams) (*models.Page[models.Booking], error)
+
+	// TransitionBookingStatus moves a booking from one status to another and
+	// records the change in booking_transitions, atomically. Cancelling a
+	// booking returns its seats or room-nights to the inventory.
+	TransitionBookingStatus(ctx context.Context, id string, from, to models.BookingStatus, reason string) (*models.Booking, error)
+
+	// GetBookingTransitions returns the status history of a booking, oldest first.
+	GetBookingTransitions(ctx context.Context, bookingID string) ([]models.BookingTransition, error)
+
+	// GetActiveBookingsByTripID returns every booking of the trip that is not
+	// cancelled, oldest first.
+	GetActiveBookingsByTripID(ctx context.Context, tripID string) ([]models.Booking, error)
 
-	// GetBookingByID fetches a single booking by its UUID primary key.
-	GetBookingByID(id string) (*models.Booking, error)
+	// GetTripSpend totals a trip's non-cancelled bookings per type and currency.
+	GetTripSpend(ctx context.Context, tripID string) ([]models.BookingSpend, error)
+}
 
-	// GetBookingsByTripID returns all bookings associated with the given trip.
-	GetBookingsByTripID(tripID string) ([]models.Booking, error)
+// bookingSort whitelists the fields bookings can be sorted by.
+var bookingSort = sortSpec[models.Booking]{
+	fields: map[string]sortField[models.Booking]{
+		"created_at":  {"created_at", "timestamptz", func(b models.Booking) string { return timeValue(b.CreatedAt) }},
+		"total_price": {"total_price_amount", "numeric", func(b models.Booking) string { return b.TotalPrice.Amount.String() }},
+	},
+	id: func(b models.Booking) string { return b.ID },
 }
 
-// CreateBooking inserts a new booking into the database.
-func (r *RepositoryPg) CreateBooking(booking models.Booking) (*models.Booking, error) {
-	if err := r.gormDB.Create(&booking).Error; err != nil {
+// CreateBooking inserts a new booking into the database. For flight bookings
+// the seat decrement and the insert share one transaction, so a booking row
+// exists if and only if its seats were taken. Room-type hotel bookings
+// reserve their room-nights in the inventory ledger the same way; hotels
+// without room types are booked as one unit and checked for overlapping stays.
+func (r *RepositoryPg) CreateBooking(ctx context.Context, booking models.Booking) (*models.Booking, error) {
+	err := r.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		switch booking.Type {
+		case models.BookingTypeFlight:
+			if err := reserveSeats(tx, booking.ReferenceID, booking.Quantity); err != nil {
+				return err
+			}
+		case models.BookingTypeHotel:
+			if booking.CheckIn == nil || booking.CheckOut == nil {
+				break
+			}
+
+			if booking.RoomTypeID != nil {
+				if err := reserveRoomNights(tx, *booking.RoomTypeID, *booking.CheckIn, *booking.CheckOut, booking.Quantity); err != nil {
+					return err
+				}
+			} else if err := reserveStay(tx, booking.ReferenceID, *booking.CheckIn, *booking.CheckOut); err != nil {
+				return err
+			}
+		}
+
+		// Link the travellers without upserting them.
+		return tx.Omit("Travellers.*").Create(&booking).Error
+	})
+	if err != nil {
 		return nil, fmt.Errorf("persistence: failed to create booking: %w", err)
 	}
 
@@ -28,27 +101,198 @@ func (r *RepositoryPg) CreateBooking(booking models.Booking) (*models.Booking, e
 }
 
 // GetBookingByID retrieves a booking by its primary key.
-func (r *RepositoryPg) GetBookingByID(id string) (*models.Booking, error) {
+func (r *RepositoryPg) GetBookingByID(ctx context.Context, id string) (*models.Booking, error) {
 	var booking models.Booking
 
-	if err := r.gormDB.First(&booking, "id = ?", id).Error; err != nil {
+	if err := r.gormDB.WithContext(ctx).Preload("Travellers", orderTravellers).First(&booking, "id = ?", id).Error; err != nil {
 		return nil, fmt.Errorf("persistence: failed to get booking with id %q: %w", id, err)
 	}
 
 	return &booking, nil
 }
 
-// GetBookingsByTripID returns all bookings for a trip ordered by creation time descending.
-// Returns an empty slice (not an error) when the trip has no bookings.
-func (r *RepositoryPg) GetBookingsByTripID(tripID string) ([]models.Booking, error) {
+// GetBookingsByTripID returns a page of a trip's bookings, by default ordered
+// by creation time descending. Returns an empty page (not an error) when the
+// trip has no bookings.
+func (r *RepositoryPg) GetBookingsByTripID(ctx context.Context, tripID string, page models.PageParams) (*models.Page[models.Booking], error) {
+	query := r.gormDB.WithContext(ctx).Model(&models.Booking{}).Preload("Travellers", orderTravellers).Where("trip_id = ?", tripID)
+
+	bookings, err := paginate(query, page, bookingSort, "-created_at")
+	if err != nil {
+		return nil, fmt.Errorf("persistence: failed to get bookings for trip %q: %w", tripID, err)
+	}
+
+	return bookings, nil
+}
+
+// TransitionBookingStatus updates the booking's status only if it still
+// equals `from`, then appends a transition row. Both writes share a single
+// transaction so the history can never disagree with the booking itself.
+func (r *RepositoryPg) TransitionBookingStatus(ctx context.Context, id string, from, to models.BookingStatus, reason string) (*models.Booking, error) {
+	var booking models.Booking
+
+	err := r.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The status predicate turns the update into a compare-and-swap.
+		result := tx.Model(&models.Booking{}).
+			Where("id = ? AND status = ?", id, from).
+			Updates(map[string]interface{}{
+				"status":     to,
+				"version":    gorm.Expr("version + 1"),
+				"updated_at": time.Now().UTC(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrBookingStatusChanged
+		}
+
+		if err := tx.Preload("Travellers", orderTravellers).First(&booking, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		// A cancelled booking no longer holds its inventory.
+		if to == models.BookingStatusCancelled {
+			if err := releaseInventory(tx, &booking); err != nil {
+				return err
+			}
+		}
+
+		transition := models.BookingTransition{
+			BookingID:  id,
+			FromStatus: from,
+			ToStatus:   to,
+			Reason:     reason,
+		}
+
+		return tx.Create(&transition).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("persistence: failed to move booking %q from %s to %s: %w", id, from, to, err)
+	}
+
+	return &booking, nil
+}
+
+// GetBookingTransitions returns all transitions for a booking ordered by
+// creation time ascending. Returns an empty slice when none exist.
+func (r *RepositoryPg) GetBookingTransitions(ctx context.Context, bookingID string) ([]models.BookingTransition, error) {
+	var transitions []models.BookingTransition
+
+	if err := r.gormDB.WithContext(ctx).
+		Where("booking_id = ?", bookingID).
+		Order("created_at ASC").
+		Find(&transitions).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get transitions for booking %q: %w", bookingID, err)
+	}
+
+	return transitions, nil
+}
+
+// GetActiveBookingsByTripID returns the trip's pending and confirmed bookings.
+// Returns an empty slice when there are none.
+func (r *RepositoryPg) GetActiveBookingsByTripID(ctx context.Context, tripID string) ([]models.Booking, error) {
 	var bookings []models.Booking
 
-	if err := r.gormDB.
-		Where("trip_id = ?", tripID).
-		Order("created_at DESC").
+	if err := r.gormDB.WithContext(ctx).Preload("Travellers", orderTravellers).
+		Where("trip_id = ? AND status <> ?", tripID, models.BookingStatusCancelled).
+		Order("created_at ASC").
 		Find(&bookings).Error; err != nil {
-		return nil, fmt.Errorf("persistence: failed to get bookings for trip %q: %w", tripID, err)
+		return nil, fmt.Errorf("persistence: failed to get active bookings for trip %q: %w", tripID, err)
 	}
 
 	return bookings, nil
-}
\ No newline at end of file
+}
+
+// GetTripSpend sums booking totals in SQL so the NUMERIC arithmetic stays
+// exact. Returns an empty slice when the trip has no active bookings.
+func (r *RepositoryPg) GetTripSpend(ctx context.Context, tripID string) ([]models.BookingSpend, error) {
+	var spend []models.BookingSpend
+
+	if err := r.gormDB.WithContext(ctx).Model(&models.Booking{}).
+		Select("type, total_price_currency AS currency, SUM(total_price_amount) AS amount, COUNT(*) AS bookings").
+		Where("trip_id = ? AND status <> ?", tripID, models.BookingStatusCancelled).
+		Group("type, total_price_currency").
+		Order("type, total_price_currency").
+		Scan(&spend).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get spend for trip %q: %w", tripID, err)
+	}
+
+	return spend, nil
+}
+
+// reserveSeats atomically takes `seats` from a flight's inventory. The
+// guarded UPDATE is a single statement, so two concurrent bookings for the
+// last seat cannot both see it as available: the second one matches no row.
+func reserveSeats(tx *gorm.DB, flightID string, seats int) error {
+	result := tx.Model(&models.Flight{}).
+		Where("id = ? AND seats_available >= ?", flightID, seats).
+		Updates(map[string]interface{}{
+			"seats_available": gorm.Expr("seats_available - ?", seats),
+			"updated_at":      time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		// Tell a missing flight apart from a sold-out one.
+		var flight models.Flight
+		if err := tx.Select("id").First(&flight, "id = ?", flightID).Error; err != nil {
+			return err
+		}
+
+		return ErrInsufficientSeats
+	}
+
+	return nil
+}
+
+// releaseInventory gives back whatever inventory a booking holds: seats for
+// flights and room-nights for room-type hotel bookings.
+func releaseInventory(tx *gorm.DB, booking *models.Booking) error {
+	switch {
+	case booking.Type == models.BookingTypeFlight:
+		return releaseSeats(tx, booking.ReferenceID, booking.Quantity)
+	case booking.RoomTypeID != nil && booking.CheckIn != nil && booking.CheckOut != nil:
+		return releaseRoomNights(tx, *booking.RoomTypeID, *booking.CheckIn, *booking.CheckOut, booking.Quantity)
+	}
+
+	return nil
+}
+
+// releaseSeats returns `seats` to a flight's inventory.
+func releaseSeats(tx *gorm.DB, flightID string, seats int) error {
+	return tx.Model(&models.Flight{}).
+		Where("id = ?", flightID).
+		Updates(map[string]interface{}{
+			"seats_available": gorm.Expr("seats_available + ?", seats),
+			"updated_at":      time.Now().UTC(),
+		}).Error
+}
+
+// reserveStay checks that a hotel has no live booking overlapping
+// [checkIn, checkOut). The hotel row is locked FOR UPDATE first so two
+// concurrent bookings for the same nights are serialised and the second one
+// sees the first.
+func reserveStay(tx *gorm.DB, hotelID string, checkIn, checkOut time.Time) error {
+	var hotel models.Hotel
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&hotel, "id = ?", hotelID).Error; err != nil {
+		return err
+	}
+
+	var overlapping int64
+	if err := overlappingStays(tx, hotelID, checkIn, checkOut).
+		Count(&overlapping).Error; err != nil {
+		return err
+	}
+
+	if overlapping > 0 {
+		return ErrHotelUnavailable
+	}
+
+	return nil
+}
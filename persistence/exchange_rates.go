@@ -0,0 +1,53 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"gorm.io/gorm/clause"
+)
+
+// ExchangeRateRepository defines database operations for exchange rates.
+type ExchangeRateRepository interface {
+	// UpsertExchangeRates stores rates, replacing any existing rate for the
+	// same pair and effective date.
+	UpsertExchangeRates(ctx context.Context, rates []models.ExchangeRate) error
+
+	// GetExchangeRate returns the rate from base to quote in effect on asOf,
+	// i.e. the one with the latest effective date not after it.
+	// Returns an error wrapping gorm.ErrRecordNotFound if there is none.
+	GetExchangeRate(ctx context.Context, base, quote string, asOf time.Time) (*models.ExchangeRate, error)
+}
+
+// UpsertExchangeRates inserts all rates in one statement, overwriting the
+// rate of rows whose (base, quote, effective_date) already exist.
+func (r *RepositoryPg) UpsertExchangeRates(ctx context.Context, rates []models.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+
+	if err := r.gormDB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "base"}, {Name: "quote"}, {Name: "effective_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate"}),
+	}).Create(&rates).Error; err != nil {
+		return fmt.Errorf("persistence: failed to store exchange rates: %w", err)
+	}
+
+	return nil
+}
+
+// GetExchangeRate picks the most recent rate effective on or before asOf.
+func (r *RepositoryPg) GetExchangeRate(ctx context.Context, base, quote string, asOf time.Time) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+
+	if err := r.gormDB.WithContext(ctx).
+		Where("base = ? AND quote = ? AND effective_date <= ?", base, quote, asOf).
+		Order("effective_date DESC").
+		First(&rate).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get %s/%s exchange rate: %w", base, quote, err)
+	}
+
+	return &rate, nil
+}
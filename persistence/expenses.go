@@ -0,0 +1,110 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"gorm.io/gorm"
+)
+
+// ExpenseRepository defines database operations for trip expenses.
+type ExpenseRepository interface {
+	// CreateExpense inserts an expense together with its shares. A second
+	// expense for the same booking fails with gorm.ErrDuplicatedKey.
+	CreateExpense(ctx context.Context, expense models.Expense) (*models.Expense, error)
+
+	// GetExpenseByID fetches a single expense and its shares.
+	GetExpenseByID(ctx context.Context, id string) (*models.Expense, error)
+
+	// GetExpensesByTripID returns a page of a trip's expenses.
+	GetExpensesByTripID(ctx context.Context, tripID string, page models.PageParams) (*models.Page[models.Expense], error)
+
+	// GetBalanceExpenses returns every expense that counts towards a trip's
+	// balances: the ad-hoc ones and those of bookings that are not cancelled.
+	GetBalanceExpenses(ctx context.Context, tripID string) ([]models.Expense, error)
+
+	// DeleteExpense hard-deletes the expense with the given ID and its shares.
+	DeleteExpense(ctx context.Context, id string) error
+}
+
+// expenseSort whitelists the fields expenses can be sorted by.
+var expenseSort = sortSpec[models.Expense]{
+	fields: map[string]sortField[models.Expense]{
+		"created_at": {"created_at", "timestamptz", func(e models.Expense) string { return timeValue(e.CreatedAt) }},
+		"amount":     {"amount_amount", "numeric", func(e models.Expense) string { return e.Amount.Amount.String() }},
+	},
+	id: func(e models.Expense) string { return e.ID },
+}
+
+// orderShares preloads an expense's shares in a stable order.
+func orderShares(db *gorm.DB) *gorm.DB {
+	return db.Order("user_id ASC")
+}
+
+// CreateExpense inserts the expense and its shares in one transaction.
+func (r *RepositoryPg) CreateExpense(ctx context.Context, expense models.Expense) (*models.Expense, error) {
+	if err := r.gormDB.WithContext(ctx).Create(&expense).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to create expense: %w", err)
+	}
+
+	return &expense, nil
+}
+
+// GetExpenseByID retrieves an expense by its primary key.
+func (r *RepositoryPg) GetExpenseByID(ctx context.Context, id string) (*models.Expense, error) {
+	var expense models.Expense
+
+	if err := r.gormDB.WithContext(ctx).Preload("Shares", orderShares).First(&expense, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get expense with id %q: %w", id, err)
+	}
+
+	return &expense, nil
+}
+
+// GetExpensesByTripID returns a page of the trip's expenses, by default
+// newest first.
+func (r *RepositoryPg) GetExpensesByTripID(ctx context.Context, tripID string, page models.PageParams) (*models.Page[models.Expense], error) {
+	query := r.gormDB.WithContext(ctx).Model(&models.Expense{}).Preload("Shares", orderShares).Where("trip_id = ?", tripID)
+
+	expenses, err := paginate(query, page, expenseSort, "-created_at")
+	if err != nil {
+		return nil, fmt.Errorf("persistence: failed to get expenses for trip %q: %w", tripID, err)
+	}
+
+	return expenses, nil
+}
+
+// GetBalanceExpenses lists the trip's counted expenses, oldest first.
+// Returns an empty slice when there are none.
+func (r *RepositoryPg) GetBalanceExpenses(ctx context.Context, tripID string) ([]models.Expense, error) {
+	var expenses []models.Expense
+
+	cancelled := r.gormDB.Model(&models.Booking{}).Select("id").Where("status = ?", models.BookingStatusCancelled)
+
+	if err := r.gormDB.WithContext(ctx).Preload("Shares", orderShares).
+		Where("trip_id = ?", tripID).
+		Where("booking_id IS NULL OR booking_id NOT IN (?)", cancelled).
+		Order("created_at ASC, id ASC").
+		Find(&expenses).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get balance expenses for trip %q: %w", tripID, err)
+	}
+
+	return expenses, nil
+}
+
+// DeleteExpense removes the expense row with the given ID; its shares go
+// with it through the foreign key's cascade.
+// Returns an error if no row was deleted (i.e. ID not found).
+func (r *RepositoryPg) DeleteExpense(ctx context.Context, id string) error {
+	result := r.gormDB.WithContext(ctx).Delete(&models.Expense{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("persistence: failed to delete expense with id %q: %w", id, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("persistence: no expense found with id %q: %w", id, gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
@@ -1,26 +1,47 @@
 package persistence
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"gorm.io/gorm/clause"
 )
 
 // FlightRepository defines database operations for flights.
 type FlightRepository interface {
 	// CreateFlight inserts a new flight record and returns the persisted model.
-	CreateFlight(flight models.Flight) (*models.Flight, error)
+	CreateFlight(ctx context.Context, flight models.Flight) (*models.Flight, error)
 
 	// GetFlightByID fetches a single flight by its UUID primary key.
-	GetFlightByID(id string) (*models.Flight, error)
+	GetFlightByID(ctx context.Context, id string) (*models.Flight, error)
+
+	// GetFlightsByIDs returns the flights with the given IDs.
+	// Missing IDs are omitted.
+	GetFlightsByIDs(ctx context.Context, ids []string) ([]models.Flight, error)
+
+	// GetFlightByIDForUpdate fetches a flight and locks its row for the rest of
+	// the transaction. Use it inside WithTx when the row guards inventory.
+	GetFlightByIDForUpdate(ctx context.Context, id string) (*models.Flight, error)
 
 	// GetAllFlights returns flights, optionally filtered by origin and/or destination.
-	GetAllFlights(origin, destination string) ([]models.Flight, error)
+	GetAllFlights(ctx context.Context, origin, destination string, page models.PageParams) (*models.Page[models.Flight], error)
+}
+
+// flightSort whitelists the fields flights can be sorted by.
+var flightSort = sortSpec[models.Flight]{
+	fields: map[string]sortField[models.Flight]{
+		"departure_time": {"departure_time", "timestamptz", func(f models.Flight) string { return timeValue(f.DepartureTime) }},
+		"arrival_time":   {"arrival_time", "timestamptz", func(f models.Flight) string { return timeValue(f.ArrivalTime) }},
+		"price":          {"price_amount", "numeric", func(f models.Flight) string { return f.Price.Amount.String() }},
+		"created_at":     {"created_at", "timestamptz", func(f models.Flight) string { return timeValue(f.CreatedAt) }},
+	},
+	id: func(f models.Flight) string { return f.ID },
 }
 
 // CreateFlight inserts a new flight into the database.
-func (r *RepositoryPg) CreateFlight(flight models.Flight) (*models.Flight, error) {
-	if err := r.gormDB.Create(&flight).Error; err != nil {
+func (r *RepositoryPg) CreateFlight(ctx context.Context, flight models.Flight) (*models.Flight, error) {
+	if err := r.gormDB.WithContext(ctx).Create(&flight).Error; err != nil {
 		return nil, fmt.Errorf("persistence: failed to create flight: %w", err)
 	}
 
@@ -28,20 +49,49 @@ func (r *RepositoryPg) CreateFlight(flight models.Flight) (*models.Flight, error
 }
 
 // GetFlightByID retrieves a flight by its primary key.
-func (r *RepositoryPg) GetFlightByID(id string) (*models.Flight, error) {
+func (r *RepositoryPg) GetFlightByID(ctx context.Context, id string) (*models.Flight, error) {
 	var flight models.Flight
 
-	if err := r.gormDB.First(&flight, "id = ?", id).Error; err != nil {
+	if err := r.gormDB.WithContext(ctx).First(&flight, "id = ?", id).Error; err != nil {
 		return nil, fmt.Errorf("persistence: failed to get flight with id %q: %w", id, err)
 	}
 
 	return &flight, nil
 }
 
-// GetAllFlights returns all flights ordered by departure_time ascending.
+// GetFlightsByIDs loads the flights in ids, ordered by ID.
+func (r *RepositoryPg) GetFlightsByIDs(ctx context.Context, ids []string) ([]models.Flight, error) {
+	var flights []models.Flight
+
+	if len(ids) == 0 {
+		return flights, nil
+	}
+
+	if err := r.gormDB.WithContext(ctx).Where("id IN ?", ids).Order("id ASC").Find(&flights).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get flights: %w", err)
+	}
+
+	return flights, nil
+}
+
+// GetFlightByIDForUpdate retrieves a flight by its primary key and locks the row
+// FOR UPDATE until the surrounding transaction ends.
+func (r *RepositoryPg) GetFlightByIDForUpdate(ctx context.Context, id string) (*models.Flight, error) {
+	var flight models.Flight
+
+	if err := r.gormDB.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&flight, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to lock flight with id %q: %w", id, err)
+	}
+
+	return &flight, nil
+}
+
+// GetAllFlights returns a page of flights, by default ordered by departure_time ascending.
 // Non-empty origin / destination values are applied as case-insensitive filters.
-func (r *RepositoryPg) GetAllFlights(origin, destination string) ([]models.Flight, error) {
-	query := r.gormDB.Model(&models.Flight{})
+func (r *RepositoryPg) GetAllFlights(ctx context.Context, origin, destination string, page models.PageParams) (*models.Page[models.Flight], error) {
+	query := r.gormDB.WithContext(ctx).Model(&models.Flight{})
 
 	if origin != "" {
 		query = query.Where("origin ILIKE ?", "%"+origin+"%")
@@ -51,10 +101,10 @@ func (r *RepositoryPg) GetAllFlights(origin, destination string) ([]models.Fligh
 		query = query.Where("destination ILIKE ?", "%"+destination+"%")
 	}
 
-	var flights []models.Flight
-	if err := query.Order("departure_time ASC").Find(&flights).Error; err != nil {
+	flights, err := paginate(query, page, flightSort, "departure_time")
+	if err != nil {
 		return nil, fmt.Errorf("persistence: failed to list flights: %w", err)
 	}
 
 	return flights, nil
-}
\ No newline at end of file
+}
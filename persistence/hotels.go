@@ -1,26 +1,51 @@
 package persistence
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // HotelRepository defines database operations for hotels.
 type HotelRepository interface {
 	// CreateHotel inserts a new hotel record and returns the persisted model.
-	CreateHotel(hotel models.Hotel) (*models.Hotel, error)
+	CreateHotel(ctx context.Context, hotel models.Hotel) (*models.Hotel, error)
 
 	// GetHotelByID fetches a single hotel by its UUID primary key.
-	GetHotelByID(id string) (*models.Hotel, error)
+	GetHotelByID(ctx context.Context, id string) (*models.Hotel, error)
 
-	// GetAllHotels returns every hotel, optionally filtered by location.
-	GetAllHotels(location string) ([]models.Hotel, error)
+	// GetHotelsByIDs returns the hotels with the given IDs.
+	// Missing IDs are omitted.
+	GetHotelsByIDs(ctx context.Context, ids []string) ([]models.Hotel, error)
+
+	// GetHotelByIDForUpdate fetches a hotel and locks its row for the rest of
+	// the transaction. Use it inside WithTx when the row guards inventory.
+	GetHotelByIDForUpdate(ctx context.Context, id string) (*models.Hotel, error)
+
+	// GetAllHotels returns every hotel, optionally filtered by location and
+	// by availability for a range of nights.
+	GetAllHotels(ctx context.Context, params models.HotelSearchParams, page models.PageParams) (*models.Page[models.Hotel], error)
+}
+
+// hotelSort whitelists the fields hotels can be sorted by.
+// rating is nullable, so unrated hotels sort as 0.
+var hotelSort = sortSpec[models.Hotel]{
+	fields: map[string]sortField[models.Hotel]{
+		"rating":          {"COALESCE(rating, 0)", "numeric", func(h models.Hotel) string { return floatValue(h.Rating) }},
+		"price_per_night": {"price_per_night_amount", "numeric", func(h models.Hotel) string { return h.PricePerNight.Amount.String() }},
+		"name":            {"name", "varchar", func(h models.Hotel) string { return h.Name }},
+		"created_at":      {"created_at", "timestamptz", func(h models.Hotel) string { return timeValue(h.CreatedAt) }},
+	},
+	id: func(h models.Hotel) string { return h.ID },
 }
 
 // CreateHotel inserts a new hotel into the database.
-func (r *RepositoryPg) CreateHotel(hotel models.Hotel) (*models.Hotel, error) {
-	if err := r.gormDB.Create(&hotel).Error; err != nil {
+func (r *RepositoryPg) CreateHotel(ctx context.Context, hotel models.Hotel) (*models.Hotel, error) {
+	if err := r.gormDB.WithContext(ctx).Create(&hotel).Error; err != nil {
 		return nil, fmt.Errorf("persistence: failed to create hotel: %w", err)
 	}
 
@@ -28,29 +53,99 @@ func (r *RepositoryPg) CreateHotel(hotel models.Hotel) (*models.Hotel, error) {
 }
 
 // GetHotelByID retrieves a hotel by its primary key.
-func (r *RepositoryPg) GetHotelByID(id string) (*models.Hotel, error) {
+func (r *RepositoryPg) GetHotelByID(ctx context.Context, id string) (*models.Hotel, error) {
 	var hotel models.Hotel
 
-	if err := r.gormDB.First(&hotel, "id = ?", id).Error; err != nil {
+	if err := r.gormDB.WithContext(ctx).First(&hotel, "id = ?", id).Error; err != nil {
 		return nil, fmt.Errorf("persistence: failed to get hotel with id %q: %w", id, err)
 	}
 
 	return &hotel, nil
 }
 
-// GetAllHotels returns all hotels ordered by rating descending.
+// GetHotelsByIDs loads the hotels in ids, ordered by ID.
+func (r *RepositoryPg) GetHotelsByIDs(ctx context.Context, ids []string) ([]models.Hotel, error) {
+	var hotels []models.Hotel
+
+	if len(ids) == 0 {
+		return hotels, nil
+	}
+
+	if err := r.gormDB.WithContext(ctx).Where("id IN ?", ids).Order("id ASC").Find(&hotels).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get hotels: %w", err)
+	}
+
+	return hotels, nil
+}
+
+// GetHotelByIDForUpdate retrieves a hotel by its primary key and locks the row
+// FOR UPDATE until the surrounding transaction ends.
+func (r *RepositoryPg) GetHotelByIDForUpdate(ctx context.Context, id string) (*models.Hotel, error) {
+	var hotel models.Hotel
+
+	if err := r.gormDB.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&hotel, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to lock hotel with id %q: %w", id, err)
+	}
+
+	return &hotel, nil
+}
+
+// GetAllHotels returns a page of hotels, by default ordered by rating descending.
 // When location is non-empty it is applied as a case-insensitive partial filter.
-func (r *RepositoryPg) GetAllHotels(location string) ([]models.Hotel, error) {
-	query := r.gormDB.Model(&models.Hotel{})
+// When a check-in/check-out range is given, hotels whose availability window
+// does not cover it are excluded, as are hotels with no room free on every
+// night: for hotels without room types that means any overlapping booking.
+func (r *RepositoryPg) GetAllHotels(ctx context.Context, params models.HotelSearchParams, page models.PageParams) (*models.Page[models.Hotel], error) {
+	query := r.gormDB.WithContext(ctx).Model(&models.Hotel{})
 
-	if location != "" {
-		query = query.Where("location ILIKE ?", "%"+location+"%")
+	if params.Location != "" {
+		query = query.Where("location ILIKE ?", "%"+params.Location+"%")
 	}
 
-	var hotels []models.Hotel
-	if err := query.Order("rating DESC").Find(&hotels).Error; err != nil {
+	if !params.CheckIn.IsZero() && !params.CheckOut.IsZero() {
+		query = query.
+			Where("available_from IS NULL OR available_from <= ?", params.CheckIn).
+			Where("available_to IS NULL OR available_to >= ?", params.CheckOut).
+			Where("(NOT EXISTS (?) AND NOT EXISTS (?)) OR EXISTS (?)",
+				r.gormDB.WithContext(ctx).Model(&models.RoomType{}).Select("1").Where("room_types.hotel_id = hotels.id"),
+				overlappingStays(r.gormDB, gorm.Expr("hotels.id"), params.CheckIn, params.CheckOut),
+				freeRoomTypes(r.gormDB, params.CheckIn, params.CheckOut))
+	}
+
+	hotels, err := paginate(query, page, hotelSort, "-rating")
+	if err != nil {
 		return nil, fmt.Errorf("persistence: failed to list hotels: %w", err)
 	}
 
 	return hotels, nil
-}
\ No newline at end of file
+}
+
+// overlappingStays builds a query over live hotel bookings for `hotelID`
+// whose nights intersect [checkIn, checkOut). hotelID is either a plain ID or
+// a gorm.Expr column reference when used as a correlated sub-query.
+func overlappingStays(db *gorm.DB, hotelID interface{}, checkIn, checkOut time.Time) *gorm.DB {
+	return db.Model(&models.Booking{}).
+		Select("1").
+		Where("bookings.type = ?", models.BookingTypeHotel).
+		Where("bookings.reference_id = ?", hotelID).
+		Where("bookings.status <> ?", models.BookingStatusCancelled).
+		Where("bookings.check_in < ? AND bookings.check_out > ?", checkOut, checkIn)
+}
+
+// freeRoomTypes builds a correlated sub-query over the room types of the
+// outer `hotels` row that have at least one room free on every night in
+// [checkIn, checkOut).
+func freeRoomTypes(db *gorm.DB, checkIn, checkOut time.Time) *gorm.DB {
+	fullNights := db.Model(&models.RoomNight{}).
+		Select("1").
+		Where("room_nights.room_type_id = room_types.id").
+		Where("room_nights.night >= ? AND room_nights.night < ?", checkIn, checkOut).
+		Where("room_nights.rooms_reserved >= room_types.room_count")
+
+	return db.Model(&models.RoomType{}).
+		Select("1").
+		Where("room_types.hotel_id = hotels.id").
+		Where("NOT EXISTS (?)", fullNights)
+}
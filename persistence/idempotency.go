@@ -0,0 +1,97 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyRepository defines database operations for idempotency keys.
+type IdempotencyRepository interface {
+	// ReserveIdempotencyKey stores a new in-progress key unless an unexpired
+	// one with the same user and key exists. It returns the stored record and
+	// whether this call created it; when it did not, the record is the one
+	// already held.
+	ReserveIdempotencyKey(ctx context.Context, record models.IdempotencyKey) (*models.IdempotencyKey, bool, error)
+
+	// CompleteIdempotencyKey records the response of an in-progress key.
+	CompleteIdempotencyKey(ctx context.Context, userID, key string, statusCode int, contentType string, body []byte) error
+
+	// ReleaseIdempotencyKey deletes an in-progress key so the request can be
+	// retried from scratch. Completed keys are left alone.
+	ReleaseIdempotencyKey(ctx context.Context, userID, key string) error
+
+	// PurgeExpiredIdempotencyKeys deletes keys that expired before `now`
+	// and returns how many were removed.
+	PurgeExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
+// ReserveIdempotencyKey inserts the record in one statement. An expired key
+// is overwritten by the ON CONFLICT update; a live one makes the statement a
+// no-op, in which case the live record is loaded and returned.
+func (r *RepositoryPg) ReserveIdempotencyKey(ctx context.Context, record models.IdempotencyKey) (*models.IdempotencyKey, bool, error) {
+	result := r.gormDB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"fingerprint", "status_code", "content_type", "response_body", "created_at", "expires_at",
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "idempotency_keys.expires_at <= NOW()"},
+		}},
+	}).Create(&record)
+	if result.Error != nil {
+		return nil, false, fmt.Errorf("persistence: failed to reserve idempotency key %q: %w", record.Key, result.Error)
+	}
+
+	if result.RowsAffected == 1 {
+		return &record, true, nil
+	}
+
+	var existing models.IdempotencyKey
+	if err := r.gormDB.WithContext(ctx).
+		First(&existing, "user_id = ? AND key = ?", record.UserID, record.Key).Error; err != nil {
+		return nil, false, fmt.Errorf("persistence: failed to get idempotency key %q: %w", record.Key, err)
+	}
+
+	return &existing, false, nil
+}
+
+// CompleteIdempotencyKey stores the response on a key that is still in
+// progress.
+func (r *RepositoryPg) CompleteIdempotencyKey(ctx context.Context, userID, key string, statusCode int, contentType string, body []byte) error {
+	if err := r.gormDB.WithContext(ctx).Model(&models.IdempotencyKey{}).
+		Where("user_id = ? AND key = ? AND status_code IS NULL", userID, key).
+		Updates(map[string]interface{}{
+			"status_code":   statusCode,
+			"content_type":  contentType,
+			"response_body": body,
+		}).Error; err != nil {
+		return fmt.Errorf("persistence: failed to complete idempotency key %q: %w", key, err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey deletes the key if it is still in progress.
+func (r *RepositoryPg) ReleaseIdempotencyKey(ctx context.Context, userID, key string) error {
+	if err := r.gormDB.WithContext(ctx).
+		Where("user_id = ? AND key = ? AND status_code IS NULL", userID, key).
+		Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return fmt.Errorf("persistence: failed to release idempotency key %q: %w", key, err)
+	}
+
+	return nil
+}
+
+// PurgeExpiredIdempotencyKeys removes every expired key in one DELETE.
+func (r *RepositoryPg) PurgeExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	result := r.gormDB.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("persistence: failed to purge expired idempotency keys: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,168 @@
+package persistence
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidPage is returned when a list request names a sort field that is
+// not whitelisted or carries a cursor that cannot be used.
+var ErrInvalidPage = errors.New("persistence: invalid pagination parameters")
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// sortField describes one whitelisted sort key of a listed model T.
+// expr is a trusted SQL expression that must never be NULL (wrap nullable
+// columns in COALESCE), sqlType is what cursor values are cast back to, and
+// value renders the same expression from a loaded row.
+type sortField[T any] struct {
+	expr    string
+	sqlType string
+	value   func(T) string
+}
+
+// sortSpec is the per-model pagination configuration: the whitelisted sort
+// keys and how to read a row's ID, which breaks ties between equal keys.
+type sortSpec[T any] struct {
+	fields map[string]sortField[T]
+	id     func(T) string
+}
+
+// pageCursor is the decoded form of the opaque cursor handed to clients.
+type pageCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// paginate applies keyset pagination to query and loads one page of T.
+// Rows are ordered by (sort expression, id) so the order is total, and the
+// cursor resumes strictly after the last row of the previous page; unlike
+// OFFSET this stays correct when rows are inserted between requests.
+func paginate[T any](query *gorm.DB, page models.PageParams, spec sortSpec[T], defaultSort string) (*models.Page[T], error) {
+	sortKey := page.Sort
+	if sortKey == "" {
+		sortKey = defaultSort
+	}
+
+	name, desc := strings.TrimPrefix(sortKey, "-"), strings.HasPrefix(sortKey, "-")
+
+	field, ok := spec.fields[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported sort %q, valid fields are %s", ErrInvalidPage, name, spec.fieldNames())
+	}
+
+	limit := page.Limit
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	direction, comparator := "ASC", ">"
+	if desc {
+		direction, comparator = "DESC", "<"
+	}
+
+	if page.Cursor != "" {
+		cursor, err := decodeCursor(page.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		if cursor.Sort != sortKey {
+			return nil, fmt.Errorf("%w: cursor was issued for sort %q, not %q", ErrInvalidPage, cursor.Sort, sortKey)
+		}
+
+		query = query.Where(
+			fmt.Sprintf("(%s, id) %s (CAST(? AS %s), CAST(? AS uuid))", field.expr, comparator, field.sqlType),
+			cursor.Value, cursor.ID,
+		)
+	}
+
+	// Fetch one extra row to learn whether another page follows.
+	var items []T
+	if err := query.
+		Order(fmt.Sprintf("%s %s, id %s", field.expr, direction, direction)).
+		Limit(limit + 1).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	// Always serialise an empty page as [] rather than null.
+	if items == nil {
+		items = []T{}
+	}
+
+	result := &models.Page[T]{Items: items}
+
+	if len(items) > limit {
+		last := items[limit-1]
+		result.Items = items[:limit]
+		result.NextCursor = encodeCursor(pageCursor{
+			Sort:  sortKey,
+			Value: field.value(last),
+			ID:    spec.id(last),
+		})
+	}
+
+	return result, nil
+}
+
+// fieldNames lists the whitelisted sort keys for error messages.
+func (s sortSpec[T]) fieldNames() string {
+	names := make([]string, 0, len(s.fields))
+	for name := range s.fields {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+func encodeCursor(cursor pageCursor) string {
+	raw, _ := json.Marshal(cursor) // a struct of strings always marshals
+
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(encoded string) (pageCursor, error) {
+	var cursor pageCursor
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, fmt.Errorf("%w: malformed cursor", ErrInvalidPage)
+	}
+
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.ID == "" {
+		return cursor, fmt.Errorf("%w: malformed cursor", ErrInvalidPage)
+	}
+
+	return cursor, nil
+}
+
+// Value renderers shared by the sort specs. They must produce text that
+// CASTs back to exactly the value the SQL expression yields for the row.
+
+func timeValue(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func floatValue(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
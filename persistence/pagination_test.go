@@ -0,0 +1,225 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	valid := encodeCursor(pageCursor{Sort: "-created_at", Value: "2024-01-01T00:00:00Z", ID: "a"})
+
+	tests := []struct {
+		name    string
+		cursor  string
+		want    pageCursor
+		wantErr bool
+	}{
+		{name: "round trip", cursor: valid, want: pageCursor{Sort: "-created_at", Value: "2024-01-01T00:00:00Z", ID: "a"}},
+		{name: "not base64", cursor: "!!!", wantErr: true},
+		{name: "padded base64", cursor: valid + "=", wantErr: true},
+		{name: "not json", cursor: "bm90IGpzb24", wantErr: true},
+		{name: "missing id", cursor: encodeCursor(pageCursor{Sort: "title", Value: "x"}), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCursor(tt.cursor)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPage) {
+					t.Fatalf("decodeCursor(%q) = %v, %v, want ErrInvalidPage", tt.cursor, got, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("decodeCursor(%q) error: %v", tt.cursor, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("decodeCursor(%q) = %+v, want %+v", tt.cursor, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	rows := [][]driver.Value{
+		{"00000000-0000-0000-0000-000000000001", "Rome", day(1)},
+		{"00000000-0000-0000-0000-000000000002", "Paris", day(2)},
+		{"00000000-0000-0000-0000-000000000003", "Oslo", day(3)},
+	}
+
+	cursorAfterParis := encodeCursor(pageCursor{Sort: "start_date", Value: timeValue(day(2)), ID: "00000000-0000-0000-0000-000000000002"})
+
+	tests := []struct {
+		name       string
+		page       models.PageParams
+		rows       [][]driver.Value
+		wantSQL    []string
+		wantArgs   []driver.Value
+		wantTitles []string
+		wantCursor string
+		wantErr    bool
+	}{
+		{
+			name:       "first page with more to come",
+			page:       models.PageParams{Limit: 2},
+			rows:       rows,
+			wantSQL:    []string{"ORDER BY start_date ASC, id ASC", "LIMIT 3"},
+			wantTitles: []string{"Rome", "Paris"},
+			wantCursor: cursorAfterParis,
+		},
+		{
+			name:       "last page",
+			page:       models.PageParams{Limit: 2, Cursor: cursorAfterParis},
+			rows:       rows[2:],
+			wantSQL:    []string{"(start_date, id) > (CAST($1 AS timestamptz), CAST($2 AS uuid))", "ORDER BY start_date ASC, id ASC"},
+			wantArgs:   []driver.Value{timeValue(day(2)), "00000000-0000-0000-0000-000000000002"},
+			wantTitles: []string{"Oslo"},
+		},
+		{
+			name:       "descending",
+			page:       models.PageParams{Limit: 5, Sort: "-title"},
+			rows:       rows,
+			wantSQL:    []string{"ORDER BY title DESC, id DESC", "LIMIT 6"},
+			wantTitles: []string{"Rome", "Paris", "Oslo"},
+		},
+		{
+			name:       "default and capped limits",
+			page:       models.PageParams{Limit: 1000},
+			wantSQL:    []string{"LIMIT 101"},
+			wantTitles: []string{},
+		},
+		{
+			name:    "unknown sort",
+			page:    models.PageParams{Sort: "destination"},
+			wantErr: true,
+		},
+		{
+			name:    "cursor for another sort",
+			page:    models.PageParams{Sort: "-start_date", Cursor: cursorAfterParis},
+			wantErr: true,
+		},
+		{
+			name:    "malformed cursor",
+			page:    models.PageParams{Cursor: "???"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &fakeConn{columns: []string{"id", "title", "start_date"}, rows: tt.rows}
+
+			page, err := paginate(openFakeDB(t, conn).Model(&models.Trip{}), tt.page, tripSort, "start_date")
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPage) {
+					t.Fatalf("paginate = %v, %v, want ErrInvalidPage", page, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("paginate error: %v", err)
+			}
+
+			for _, fragment := range tt.wantSQL {
+				if !strings.Contains(conn.query, fragment) {
+					t.Errorf("query %q does not contain %q", conn.query, fragment)
+				}
+			}
+
+			for i, arg := range tt.wantArgs {
+				if i >= len(conn.args) || conn.args[i] != arg {
+					t.Errorf("query args = %v, want %v first", conn.args, tt.wantArgs)
+					break
+				}
+			}
+
+			titles := []string{}
+			for _, trip := range page.Items {
+				titles = append(titles, trip.Title)
+			}
+
+			if strings.Join(titles, ",") != strings.Join(tt.wantTitles, ",") {
+				t.Errorf("page items = %v, want %v", titles, tt.wantTitles)
+			}
+
+			if page.NextCursor != tt.wantCursor {
+				t.Errorf("next cursor = %q, want %q", page.NextCursor, tt.wantCursor)
+			}
+		})
+	}
+}
+
+// openFakeDB returns a GORM handle speaking the Postgres dialect to conn.
+func openFakeDB(t *testing.T, conn *fakeConn) *gorm.DB {
+	t.Helper()
+
+	sqlDB := sql.OpenDB(fakeConnector{conn})
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// fakeConn is a database/sql connection that records the last query and
+// answers every query with the same rows.
+type fakeConn struct {
+	columns []string
+	rows    [][]driver.Value
+	query   string
+	args    []driver.Value
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.query, c.args = query, nil
+	for _, arg := range args {
+		c.args = append(c.args, arg.Value)
+	}
+
+	return &fakeRows{columns: c.columns, rows: c.rows}, nil
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+type fakeConnector struct{ conn *fakeConn }
+
+func (f fakeConnector) Connect(context.Context) (driver.Conn, error) { return f.conn, nil }
+func (f fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+
+	return nil
+}
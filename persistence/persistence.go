@@ -1,6 +1,7 @@
 package persistence
 
 import (
+	"context"
 	"fmt"
 
 	"gorm.io/gorm"
@@ -12,9 +13,29 @@ import (
 // making it straightforward to swap in a mock for unit tests.
 type Repository interface {
 	TripRepository
+	TripMemberRepository
 	HotelRepository
+	RoomTypeRepository
 	FlightRepository
+	ActivityRepository
 	BookingRepository
+	AgentRepository
+	TravellerRepository
+	ExpenseRepository
+	ExchangeRateRepository
+	AuditRepository
+	IdempotencyRepository
+	UnitOfWork
+}
+
+// UnitOfWork lets callers group several repository calls into one atomic
+// database transaction.
+type UnitOfWork interface {
+	// WithTx runs fn with a Repository bound to a single transaction. The
+	// transaction commits when fn returns nil and rolls back when it returns
+	// an error or panics. Row-locking reads (the ...ForUpdate methods) only
+	// hold their locks for the duration of such a transaction.
+	WithTx(ctx context.Context, fn func(repo Repository) error) error
 }
 
 // RepositoryPg is the PostgreSQL implementation of Repository.
@@ -33,4 +54,13 @@ func NewRepository(db *gorm.DB) (*RepositoryPg, error) {
 	}
 
 	return &RepositoryPg{gormDB: db}, nil
-}
\ No newline at end of file
+}
+
+// WithTx opens a transaction and hands fn a RepositoryPg that runs every
+// query on it. Repository methods that open their own transaction while
+// inside WithTx become savepoints of the outer one.
+func (r *RepositoryPg) WithTx(ctx context.Context, fn func(repo Repository) error) error {
+	return r.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&RepositoryPg{gormDB: tx})
+	})
+}
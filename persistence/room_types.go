@@ -0,0 +1,164 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrRoomsUnavailable is returned by CreateBooking when a room type does not
+// have enough free rooms on at least one of the requested nights.
+var ErrRoomsUnavailable = errors.New("persistence: not enough rooms available")
+
+// RoomTypeRepository defines database operations for hotel room types and
+// their per-night inventory.
+type RoomTypeRepository interface {
+	// CreateRoomType inserts a new room type and returns the persisted model.
+	CreateRoomType(ctx context.Context, roomType models.RoomType) (*models.RoomType, error)
+
+	// GetRoomTypeByID fetches a single room type by its UUID primary key.
+	GetRoomTypeByID(ctx context.Context, id string) (*models.RoomType, error)
+
+	// GetRoomTypesByIDs returns the room types with the given IDs.
+	// Missing IDs are omitted.
+	GetRoomTypesByIDs(ctx context.Context, ids []string) ([]models.RoomType, error)
+
+	// GetRoomTypeByIDForUpdate fetches a room type and locks its row for the rest of
+	// the transaction. Use it inside WithTx when the row guards inventory.
+	GetRoomTypeByIDForUpdate(ctx context.Context, id string) (*models.RoomType, error)
+
+	// GetRoomTypesByHotelID returns every room type of the given hotel.
+	GetRoomTypesByHotelID(ctx context.Context, hotelID string) ([]models.RoomType, error)
+
+	// GetRoomsAvailable returns how many rooms of a type are free on every
+	// night in [checkIn, checkOut).
+	GetRoomsAvailable(ctx context.Context, roomTypeID string, checkIn, checkOut time.Time) (int, error)
+}
+
+// CreateRoomType inserts a new room type into the database.
+func (r *RepositoryPg) CreateRoomType(ctx context.Context, roomType models.RoomType) (*models.RoomType, error) {
+	if err := r.gormDB.WithContext(ctx).Create(&roomType).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to create room type: %w", err)
+	}
+
+	return &roomType, nil
+}
+
+// GetRoomTypeByID retrieves a room type by its primary key.
+func (r *RepositoryPg) GetRoomTypeByID(ctx context.Context, id string) (*models.RoomType, error) {
+	var roomType models.RoomType
+
+	if err := r.gormDB.WithContext(ctx).First(&roomType, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get room type with id %q: %w", id, err)
+	}
+
+	return &roomType, nil
+}
+
+// GetRoomTypesByIDs loads the room types in ids, ordered by ID.
+func (r *RepositoryPg) GetRoomTypesByIDs(ctx context.Context, ids []string) ([]models.RoomType, error) {
+	var roomTypes []models.RoomType
+
+	if len(ids) == 0 {
+		return roomTypes, nil
+	}
+
+	if err := r.gormDB.WithContext(ctx).Where("id IN ?", ids).Order("id ASC").Find(&roomTypes).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get room types: %w", err)
+	}
+
+	return roomTypes, nil
+}
+
+// GetRoomTypeByIDForUpdate retrieves a room type by its primary key and locks the row
+// FOR UPDATE until the surrounding transaction ends.
+func (r *RepositoryPg) GetRoomTypeByIDForUpdate(ctx context.Context, id string) (*models.RoomType, error) {
+	var roomType models.RoomType
+
+	if err := r.gormDB.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&roomType, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to lock room type with id %q: %w", id, err)
+	}
+
+	return &roomType, nil
+}
+
+// GetRoomTypesByHotelID returns a hotel's room types ordered by price ascending.
+// Returns an empty slice (not an error) when the hotel has none.
+func (r *RepositoryPg) GetRoomTypesByHotelID(ctx context.Context, hotelID string) ([]models.RoomType, error) {
+	var roomTypes []models.RoomType
+
+	if err := r.gormDB.WithContext(ctx).
+		Where("hotel_id = ?", hotelID).
+		Order("price_per_night_amount ASC").
+		Find(&roomTypes).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get room types for hotel %q: %w", hotelID, err)
+	}
+
+	return roomTypes, nil
+}
+
+// GetRoomsAvailable subtracts the busiest night's reservations in the range
+// from the room type's room count.
+func (r *RepositoryPg) GetRoomsAvailable(ctx context.Context, roomTypeID string, checkIn, checkOut time.Time) (int, error) {
+	roomType, err := r.GetRoomTypeByID(ctx, roomTypeID)
+	if err != nil {
+		return 0, err
+	}
+
+	var peak int
+	if err := r.gormDB.WithContext(ctx).Model(&models.RoomNight{}).
+		Select("COALESCE(MAX(rooms_reserved), 0)").
+		Where("room_type_id = ? AND night >= ? AND night < ?", roomTypeID, checkIn, checkOut).
+		Scan(&peak).Error; err != nil {
+		return 0, fmt.Errorf("persistence: failed to get availability for room type %q: %w", roomTypeID, err)
+	}
+
+	return roomType.RoomCount - peak, nil
+}
+
+// reserveRoomNights adds `rooms` to the ledger for every night in
+// [checkIn, checkOut). The room type row is locked FOR UPDATE first so
+// concurrent reservations for the same type are serialised; any night that
+// would exceed the room count aborts the whole transaction.
+func reserveRoomNights(tx *gorm.DB, roomTypeID string, checkIn, checkOut time.Time, rooms int) error {
+	var roomType models.RoomType
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&roomType, "id = ?", roomTypeID).Error; err != nil {
+		return err
+	}
+
+	for night := checkIn; night.Before(checkOut); night = night.AddDate(0, 0, 1) {
+		var reserved int
+		if err := tx.Raw(`
+			INSERT INTO room_nights (room_type_id, night, rooms_reserved)
+			VALUES (?, ?, ?)
+			ON CONFLICT (room_type_id, night)
+			DO UPDATE SET rooms_reserved = room_nights.rooms_reserved + EXCLUDED.rooms_reserved
+			RETURNING rooms_reserved`,
+			roomTypeID, night, rooms,
+		).Scan(&reserved).Error; err != nil {
+			return err
+		}
+
+		if reserved > roomType.RoomCount {
+			return ErrRoomsUnavailable
+		}
+	}
+
+	return nil
+}
+
+// releaseRoomNights gives `rooms` back to the ledger for every night in
+// [checkIn, checkOut).
+func releaseRoomNights(tx *gorm.DB, roomTypeID string, checkIn, checkOut time.Time, rooms int) error {
+	return tx.Model(&models.RoomNight{}).
+		Where("room_type_id = ? AND night >= ? AND night < ?", roomTypeID, checkIn, checkOut).
+		Update("rooms_reserved", gorm.Expr("rooms_reserved - ?", rooms)).Error
+}
@@ -0,0 +1,191 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TravellerRepository defines database operations for travellers.
+type TravellerRepository interface {
+	// CreateTraveller inserts a new traveller and returns the persisted model.
+	CreateTraveller(ctx context.Context, traveller models.Traveller) (*models.Traveller, error)
+
+	// GetTravellerByID fetches a single traveller by its UUID primary key.
+	GetTravellerByID(ctx context.Context, id string) (*models.Traveller, error)
+
+	// GetTravellerByIDForUpdate fetches a traveller and locks its row for the
+	// rest of the transaction. Use it inside WithTx so the traveller cannot be
+	// deleted while it is being named on a booking, or vice versa.
+	GetTravellerByIDForUpdate(ctx context.Context, id string) (*models.Traveller, error)
+
+	// GetTravellersByIDs returns the travellers with the given IDs.
+	// Missing IDs are omitted.
+	GetTravellersByIDs(ctx context.Context, ids []string) ([]models.Traveller, error)
+
+	// GetTravellersByIDsForUpdate is GetTravellersByIDs with the rows locked
+	// for the rest of the transaction.
+	GetTravellersByIDsForUpdate(ctx context.Context, ids []string) ([]models.Traveller, error)
+
+	// GetTravellersByUserID returns a page of a user's travellers.
+	GetTravellersByUserID(ctx context.Context, userID string, page models.PageParams) (*models.Page[models.Traveller], error)
+
+	// UpdateTraveller applies a partial update map to the traveller with the
+	// given ID. Only the keys present in `updates` are written.
+	UpdateTraveller(ctx context.Context, id string, updates map[string]interface{}) (*models.Traveller, error)
+
+	// DeleteTraveller hard-deletes the traveller with the given ID, removing
+	// it from the lists of the bookings it was named on.
+	DeleteTraveller(ctx context.Context, id string) error
+
+	// TravellerHasActiveBookings reports whether the traveller is named on a
+	// booking that is not cancelled.
+	TravellerHasActiveBookings(ctx context.Context, id string) (bool, error)
+}
+
+// travellerSort whitelists the fields travellers can be sorted by.
+var travellerSort = sortSpec[models.Traveller]{
+	fields: map[string]sortField[models.Traveller]{
+		"last_name":  {"last_name", "varchar", func(t models.Traveller) string { return t.LastName }},
+		"created_at": {"created_at", "timestamptz", func(t models.Traveller) string { return timeValue(t.CreatedAt) }},
+	},
+	id: func(t models.Traveller) string { return t.ID },
+}
+
+// CreateTraveller inserts a new traveller into the database.
+func (r *RepositoryPg) CreateTraveller(ctx context.Context, traveller models.Traveller) (*models.Traveller, error) {
+	if err := r.gormDB.WithContext(ctx).Create(&traveller).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to create traveller: %w", err)
+	}
+
+	return &traveller, nil
+}
+
+// GetTravellerByID retrieves a traveller by its primary key.
+func (r *RepositoryPg) GetTravellerByID(ctx context.Context, id string) (*models.Traveller, error) {
+	var traveller models.Traveller
+
+	if err := r.gormDB.WithContext(ctx).First(&traveller, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get traveller with id %q: %w", id, err)
+	}
+
+	return &traveller, nil
+}
+
+// GetTravellerByIDForUpdate retrieves a traveller by its primary key and
+// locks the row FOR UPDATE until the surrounding transaction ends.
+func (r *RepositoryPg) GetTravellerByIDForUpdate(ctx context.Context, id string) (*models.Traveller, error) {
+	var traveller models.Traveller
+
+	if err := r.gormDB.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&traveller, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to lock traveller with id %q: %w", id, err)
+	}
+
+	return &traveller, nil
+}
+
+// GetTravellersByIDs loads the travellers in ids, ordered by ID.
+func (r *RepositoryPg) GetTravellersByIDs(ctx context.Context, ids []string) ([]models.Traveller, error) {
+	var travellers []models.Traveller
+
+	if len(ids) == 0 {
+		return travellers, nil
+	}
+
+	if err := r.gormDB.WithContext(ctx).Where("id IN ?", ids).Order("id ASC").Find(&travellers).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get travellers: %w", err)
+	}
+
+	return travellers, nil
+}
+
+// GetTravellersByIDsForUpdate loads and locks the travellers in ids. Rows are
+// locked in ID order, so two transactions locking overlapping sets cannot
+// deadlock.
+func (r *RepositoryPg) GetTravellersByIDsForUpdate(ctx context.Context, ids []string) ([]models.Traveller, error) {
+	var travellers []models.Traveller
+
+	if len(ids) == 0 {
+		return travellers, nil
+	}
+
+	if err := r.gormDB.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", ids).Order("id ASC").Find(&travellers).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to lock travellers: %w", err)
+	}
+
+	return travellers, nil
+}
+
+// GetTravellersByUserID returns a page of the user's travellers, by default
+// ordered by last name.
+func (r *RepositoryPg) GetTravellersByUserID(ctx context.Context, userID string, page models.PageParams) (*models.Page[models.Traveller], error) {
+	query := r.gormDB.WithContext(ctx).Model(&models.Traveller{}).Where("user_id = ?", userID)
+
+	travellers, err := paginate(query, page, travellerSort, "last_name")
+	if err != nil {
+		return nil, fmt.Errorf("persistence: failed to get travellers of user %q: %w", userID, err)
+	}
+
+	return travellers, nil
+}
+
+// UpdateTraveller applies the provided field map to the traveller row and
+// returns the updated record.
+func (r *RepositoryPg) UpdateTraveller(ctx context.Context, id string, updates map[string]interface{}) (*models.Traveller, error) {
+	// Confirm the traveller exists before attempting to update.
+	traveller, err := r.GetTravellerByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("persistence: update pre-check failed: %w", err)
+	}
+
+	if err := r.gormDB.WithContext(ctx).Model(traveller).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to update traveller with id %q: %w", id, err)
+	}
+
+	return traveller, nil
+}
+
+// DeleteTraveller removes the traveller row with the given ID; its
+// booking_travellers rows go with it through the foreign key's cascade.
+// Returns an error if no row was deleted (i.e. ID not found).
+func (r *RepositoryPg) DeleteTraveller(ctx context.Context, id string) error {
+	result := r.gormDB.WithContext(ctx).Delete(&models.Traveller{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("persistence: failed to delete traveller with id %q: %w", id, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("persistence: no traveller found with id %q: %w", id, gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+// TravellerHasActiveBookings looks for a pending or confirmed booking naming
+// the traveller. Bookings of soft-deleted trips count too, since the trip
+// may still be restored.
+func (r *RepositoryPg) TravellerHasActiveBookings(ctx context.Context, id string) (bool, error) {
+	var count int64
+
+	if err := r.gormDB.WithContext(ctx).Unscoped().Model(&models.Booking{}).
+		Joins("JOIN booking_travellers ON booking_travellers.booking_id = bookings.id").
+		Where("booking_travellers.traveller_id = ? AND bookings.status <> ?", id, models.BookingStatusCancelled).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("persistence: failed to check bookings of traveller %q: %w", id, err)
+	}
+
+	return count > 0, nil
+}
+
+// orderTravellers sorts preloaded booking travellers by name, so a booking
+// always lists them in the same order.
+func orderTravellers(db *gorm.DB) *gorm.DB {
+	return db.Order("last_name ASC, first_name ASC, id ASC")
+}
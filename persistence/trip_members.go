@@ -0,0 +1,186 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvitationStatusChanged is returned by SetInvitationStatus when the
+// invitation no longer has the expected status, i.e. a concurrent request
+// accepted or revoked it first.
+var ErrInvitationStatusChanged = errors.New("persistence: invitation status changed concurrently")
+
+// TripMemberRepository defines database operations for trip members and
+// invitations.
+type TripMemberRepository interface {
+	// GetTripMember fetches userID's membership of a trip. Returns an error
+	// wrapping gorm.ErrRecordNotFound if userID is not a member.
+	GetTripMember(ctx context.Context, tripID, userID string) (*models.TripMember, error)
+
+	// GetTripMembers returns a trip's members, oldest first.
+	GetTripMembers(ctx context.Context, tripID string) ([]models.TripMember, error)
+
+	// AddTripMember inserts a membership. Adding an existing member fails
+	// with gorm.ErrDuplicatedKey.
+	AddTripMember(ctx context.Context, member models.TripMember) (*models.TripMember, error)
+
+	// RemoveTripMember deletes a membership.
+	RemoveTripMember(ctx context.Context, tripID, userID string) error
+
+	// CreateInvitation inserts a pending invitation. A second pending
+	// invitation for the same trip and user fails with gorm.ErrDuplicatedKey.
+	CreateInvitation(ctx context.Context, invitation models.TripInvitation) (*models.TripInvitation, error)
+
+	// GetInvitationByIDForUpdate fetches an invitation and locks its row for
+	// the rest of the transaction.
+	GetInvitationByIDForUpdate(ctx context.Context, id string) (*models.TripInvitation, error)
+
+	// GetTripInvitations returns every invitation to a trip, newest first.
+	GetTripInvitations(ctx context.Context, tripID string) ([]models.TripInvitation, error)
+
+	// GetPendingInvitations returns the invitations waiting for userID's
+	// answer, newest first. Invitations to deleted trips are left out.
+	GetPendingInvitations(ctx context.Context, userID string) ([]models.TripInvitation, error)
+
+	// SetInvitationStatus moves an invitation from one status to another.
+	SetInvitationStatus(ctx context.Context, id string, from, to models.InvitationStatus) (*models.TripInvitation, error)
+}
+
+// GetTripMember retrieves a membership by its composite primary key.
+func (r *RepositoryPg) GetTripMember(ctx context.Context, tripID, userID string) (*models.TripMember, error) {
+	var member models.TripMember
+
+	if err := r.gormDB.WithContext(ctx).First(&member, "trip_id = ? AND user_id = ?", tripID, userID).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get member %q of trip %q: %w", userID, tripID, err)
+	}
+
+	return &member, nil
+}
+
+// GetTripMembers lists a trip's members ordered by when they joined.
+// Returns an empty slice when there are none.
+func (r *RepositoryPg) GetTripMembers(ctx context.Context, tripID string) ([]models.TripMember, error) {
+	var members []models.TripMember
+
+	if err := r.gormDB.WithContext(ctx).
+		Where("trip_id = ?", tripID).
+		Order("created_at ASC, user_id ASC").
+		Find(&members).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get members of trip %q: %w", tripID, err)
+	}
+
+	return members, nil
+}
+
+// AddTripMember inserts a new membership row.
+func (r *RepositoryPg) AddTripMember(ctx context.Context, member models.TripMember) (*models.TripMember, error) {
+	if err := r.gormDB.WithContext(ctx).Create(&member).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to add member %q to trip %q: %w", member.UserID, member.TripID, err)
+	}
+
+	return &member, nil
+}
+
+// RemoveTripMember deletes the membership.
+// Returns an error wrapping gorm.ErrRecordNotFound if it did not exist.
+func (r *RepositoryPg) RemoveTripMember(ctx context.Context, tripID, userID string) error {
+	result := r.gormDB.WithContext(ctx).Delete(&models.TripMember{}, "trip_id = ? AND user_id = ?", tripID, userID)
+	if result.Error != nil {
+		return fmt.Errorf("persistence: failed to remove member %q from trip %q: %w", userID, tripID, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("persistence: user %q is not a member of trip %q: %w", userID, tripID, gorm.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+// CreateInvitation inserts a new invitation.
+func (r *RepositoryPg) CreateInvitation(ctx context.Context, invitation models.TripInvitation) (*models.TripInvitation, error) {
+	if err := r.gormDB.WithContext(ctx).Create(&invitation).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to invite user %q to trip %q: %w", invitation.InviteeID, invitation.TripID, err)
+	}
+
+	return &invitation, nil
+}
+
+// GetInvitationByIDForUpdate retrieves an invitation by its primary key and
+// locks the row FOR UPDATE until the surrounding transaction ends.
+func (r *RepositoryPg) GetInvitationByIDForUpdate(ctx context.Context, id string) (*models.TripInvitation, error) {
+	var invitation models.TripInvitation
+
+	if err := r.gormDB.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&invitation, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get invitation with id %q: %w", id, err)
+	}
+
+	return &invitation, nil
+}
+
+// GetTripInvitations lists a trip's invitations in every status.
+// Returns an empty slice when there are none.
+func (r *RepositoryPg) GetTripInvitations(ctx context.Context, tripID string) ([]models.TripInvitation, error) {
+	var invitations []models.TripInvitation
+
+	if err := r.gormDB.WithContext(ctx).
+		Where("trip_id = ?", tripID).
+		Order("created_at DESC, id DESC").
+		Find(&invitations).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get invitations of trip %q: %w", tripID, err)
+	}
+
+	return invitations, nil
+}
+
+// GetPendingInvitations lists the user's pending invitations to trips that
+// are not deleted. Returns an empty slice when there are none.
+func (r *RepositoryPg) GetPendingInvitations(ctx context.Context, userID string) ([]models.TripInvitation, error) {
+	var invitations []models.TripInvitation
+
+	liveTrips := r.gormDB.Model(&models.Trip{}).Select("id")
+
+	if err := r.gormDB.WithContext(ctx).
+		Where("invitee_id = ? AND status = ?", userID, models.InvitationStatusPending).
+		Where("trip_id IN (?)", liveTrips).
+		Order("created_at DESC, id DESC").
+		Find(&invitations).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get pending invitations of user %q: %w", userID, err)
+	}
+
+	return invitations, nil
+}
+
+// SetInvitationStatus updates the invitation's status only if it still
+// equals `from`, then returns the updated record.
+func (r *RepositoryPg) SetInvitationStatus(ctx context.Context, id string, from, to models.InvitationStatus) (*models.TripInvitation, error) {
+	// The status predicate turns the update into a compare-and-swap.
+	result := r.gormDB.WithContext(ctx).Model(&models.TripInvitation{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]interface{}{
+			"status":     to,
+			"updated_at": time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("persistence: failed to move invitation %q from %s to %s: %w", id, from, to, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("persistence: failed to move invitation %q from %s to %s: %w", id, from, to, ErrInvitationStatusChanged)
+	}
+
+	var invitation models.TripInvitation
+
+	if err := r.gormDB.WithContext(ctx).First(&invitation, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get invitation with id %q: %w", id, err)
+	}
+
+	return &invitation, nil
+}
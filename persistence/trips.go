@@ -1,37 +1,93 @@
 package persistence
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/namkatcedrickjumtock/travel-planner/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrTripVersionChanged is returned by UpdateTrip when the trip no longer has
+// the expected version, i.e. a concurrent request updated it first.
+var ErrTripVersionChanged = errors.New("persistence: trip version changed concurrently")
+
 // TripRepository defines all database operations for trips.
 type TripRepository interface {
-	// CreateTrip inserts a new trip record and returns the persisted model.
-	CreateTrip(trip models.Trip) (*models.Trip, error)
+	// CreateTrip inserts a new trip record, with its owner as its first
+	// member, and returns the persisted model.
+	CreateTrip(ctx context.Context, trip models.Trip) (*models.Trip, error)
 
 	// GetTripByID fetches a single trip by its UUID primary key.
-	GetTripByID(id string) (*models.Trip, error)
+	GetTripByID(ctx context.Context, id string) (*models.Trip, error)
+
+	// GetTripByIDForUpdate fetches a trip and locks its row for the rest of
+	// the transaction. Use it inside WithTx to serialize deletes, re-dates
+	// and budget checks against concurrent bookings on the same trip.
+	GetTripByIDForUpdate(ctx context.Context, id string) (*models.Trip, error)
+
+	// GetTripsByUserIDs returns a page of the trips owned by any of the given
+	// users or that memberID is a member of. With neither set the page is
+	// empty.
+	GetTripsByUserIDs(ctx context.Context, userIDs []string, memberID string, page models.PageParams) (*models.Page[models.Trip], error)
+
+	// UpdateTrip applies a partial update map to the trip with the given ID
+	// if it is still at `version`, and bumps the version. Only the keys
+	// present in `updates` are written to the database.
+	UpdateTrip(ctx context.Context, id string, version int, updates map[string]interface{}) (*models.Trip, error)
+
+	// DeleteTrip soft-deletes the trip with the given ID together with its
+	// bookings. Soft-deleted rows are hidden from every other query.
+	DeleteTrip(ctx context.Context, id string) error
 
-	// GetAllTrips returns every trip in the database.
-	GetAllTrips() ([]models.Trip, error)
+	// GetDeletedTripByID fetches a soft-deleted trip and locks its row.
+	// Returns an error wrapping gorm.ErrRecordNotFound if the trip does not
+	// exist or is not deleted.
+	GetDeletedTripByID(ctx context.Context, id string) (*models.Trip, error)
 
-	// UpdateTrip applies a partial update map to the trip with the given ID.
-	// Only the keys present in `updates` are written to the database.
-	UpdateTrip(id string, updates map[string]interface{}) (*models.Trip, error)
+	// RestoreTrip undoes DeleteTrip for the trip and the bookings deleted
+	// along with it.
+	RestoreTrip(ctx context.Context, id string) (*models.Trip, error)
 
-	// DeleteTrip hard-deletes the trip with the given ID.
-	DeleteTrip(id string) error
+	// PurgeDeletedTrips permanently removes trips soft-deleted before
+	// `before`, and their bookings, returning the IDs of the removed trips.
+	PurgeDeletedTrips(ctx context.Context, before time.Time) ([]string, error)
 
-	// SearchTrips filters trips by destination and/or date range.
-	// Any zero-value filter field is ignored.
-	SearchTrips(params models.TripSearchParams) ([]models.Trip, error)
+	// SearchTrips filters trips by owners or member, destination and/or date range.
+	// The owners and member always scope the search: with neither set the
+	// page is empty. Any other zero-value filter field is ignored.
+	SearchTrips(ctx context.Context, params models.TripSearchParams, page models.PageParams) (*models.Page[models.Trip], error)
+
+	// CompleteEndedTrips marks confirmed trips whose end date is before
+	// `now` as completed and returns the IDs of the updated trips.
+	CompleteEndedTrips(ctx context.Context, now time.Time) ([]string, error)
+}
+
+// tripSort whitelists the fields trips can be sorted by.
+var tripSort = sortSpec[models.Trip]{
+	fields: map[string]sortField[models.Trip]{
+		"created_at": {"created_at", "timestamptz", func(t models.Trip) string { return timeValue(t.CreatedAt) }},
+		"start_date": {"start_date", "timestamptz", func(t models.Trip) string { return timeValue(t.StartDate) }},
+		"end_date":   {"end_date", "timestamptz", func(t models.Trip) string { return timeValue(t.EndDate) }},
+		"title":      {"title", "varchar", func(t models.Trip) string { return t.Title }},
+	},
+	id: func(t models.Trip) string { return t.ID },
 }
 
-// CreateTrip inserts a new trip into the database.
-func (r *RepositoryPg) CreateTrip(trip models.Trip) (*models.Trip, error) {
-	if err := r.gormDB.Create(&trip).Error; err != nil {
+// CreateTrip inserts a new trip and its owner's membership in one
+// transaction, so every trip has an owner member.
+func (r *RepositoryPg) CreateTrip(ctx context.Context, trip models.Trip) (*models.Trip, error) {
+	err := r.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&trip).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.TripMember{TripID: trip.ID, UserID: trip.UserID, Role: models.TripRoleOwner}).Error
+	})
+	if err != nil {
 		return nil, fmt.Errorf("persistence: failed to create trip: %w", err)
 	}
 
@@ -40,62 +96,215 @@ func (r *RepositoryPg) CreateTrip(trip models.Trip) (*models.Trip, error) {
 
 // GetTripByID retrieves a trip by its primary key.
 // Returns an error wrapping gorm.ErrRecordNotFound when no row matches.
-func (r *RepositoryPg) GetTripByID(id string) (*models.Trip, error) {
+func (r *RepositoryPg) GetTripByID(ctx context.Context, id string) (*models.Trip, error) {
 	var trip models.Trip
 
-	if err := r.gormDB.First(&trip, "id = ?", id).Error; err != nil {
+	if err := r.gormDB.WithContext(ctx).First(&trip, "id = ?", id).Error; err != nil {
 		return nil, fmt.Errorf("persistence: failed to get trip with id %q: %w", id, err)
 	}
 
 	return &trip, nil
 }
 
-// GetAllTrips returns all trips ordered by creation time descending.
-func (r *RepositoryPg) GetAllTrips() ([]models.Trip, error) {
-	var trips []models.Trip
+// GetTripByIDForUpdate retrieves a trip by its primary key and locks the row
+// FOR UPDATE until the surrounding transaction ends.
+func (r *RepositoryPg) GetTripByIDForUpdate(ctx context.Context, id string) (*models.Trip, error) {
+	var trip models.Trip
+
+	if err := r.gormDB.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&trip, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to lock trip with id %q: %w", id, err)
+	}
+
+	return &trip, nil
+}
+
+// GetTripsByUserIDs returns a page of the users' trips and memberID's shared
+// trips, by default ordered by creation time descending.
+func (r *RepositoryPg) GetTripsByUserIDs(ctx context.Context, userIDs []string, memberID string, page models.PageParams) (*models.Page[models.Trip], error) {
+	if len(userIDs) == 0 && memberID == "" {
+		return &models.Page[models.Trip]{Items: []models.Trip{}}, nil
+	}
+
+	query := r.gormDB.WithContext(ctx).Model(&models.Trip{}).Where(r.visibleTrips(userIDs, memberID))
 
-	if err := r.gormDB.Order("created_at DESC").Find(&trips).Error; err != nil {
-		return nil, fmt.Errorf("persistence: failed to list trips: %w", err)
+	trips, err := paginate(query, page, tripSort, "-created_at")
+	if err != nil {
+		return nil, fmt.Errorf("persistence: failed to list trips for users %q: %w", userIDs, err)
 	}
 
 	return trips, nil
 }
 
 // UpdateTrip applies the provided field map to the trip row and returns the
-// updated record. An empty updates map is a no-op but not an error.
-func (r *RepositoryPg) UpdateTrip(id string, updates map[string]interface{}) (*models.Trip, error) {
-	// Confirm the trip exists before attempting to update.
-	trip, err := r.GetTripByID(id)
-	if err != nil {
-		return nil, fmt.Errorf("persistence: update pre-check failed: %w", err)
+// updated record. The version predicate turns the update into a
+// compare-and-swap, so a write based on a stale read fails with
+// ErrTripVersionChanged instead of overwriting the newer one.
+func (r *RepositoryPg) UpdateTrip(ctx context.Context, id string, version int, updates map[string]interface{}) (*models.Trip, error) {
+	values := make(map[string]interface{}, len(updates)+1)
+	for column, value := range updates {
+		values[column] = value
 	}
 
-	if err := r.gormDB.Model(trip).Updates(updates).Error; err != nil {
-		return nil, fmt.Errorf("persistence: failed to update trip with id %q: %w", id, err)
+	values["version"] = gorm.Expr("version + 1")
+
+	result := r.gormDB.WithContext(ctx).Model(&models.Trip{}).
+		Where("id = ? AND version = ?", id, version).
+		Updates(values)
+	if result.Error != nil {
+		return nil, fmt.Errorf("persistence: failed to update trip with id %q: %w", id, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		// Tell a missing trip apart from a lost race.
+		if _, err := r.GetTripByID(ctx, id); err != nil {
+			return nil, fmt.Errorf("persistence: update pre-check failed: %w", err)
+		}
+
+		return nil, fmt.Errorf("persistence: trip %q is no longer at version %d: %w", id, version, ErrTripVersionChanged)
 	}
 
-	return trip, nil
+	return r.GetTripByID(ctx, id)
 }
 
-// DeleteTrip removes the trip row with the given ID.
+// DeleteTrip stamps the trip and its live bookings with the same deleted_at,
+// which is how RestoreTrip later finds the bookings to bring back. Like any
+// other write it bumps their versions, so stale ETags stop matching.
 // Returns an error if no row was deleted (i.e. ID not found).
-func (r *RepositoryPg) DeleteTrip(id string) error {
-	result := r.gormDB.Delete(&models.Trip{}, "id = ?", id)
-	if result.Error != nil {
-		return fmt.Errorf("persistence: failed to delete trip with id %q: %w", id, result.Error)
+func (r *RepositoryPg) DeleteTrip(ctx context.Context, id string) error {
+	now := time.Now().UTC()
+
+	return r.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Trip{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"deleted_at": now, "version": gorm.Expr("version + 1")})
+		if result.Error != nil {
+			return fmt.Errorf("persistence: failed to delete trip with id %q: %w", id, result.Error)
+		}
+
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("persistence: no trip found with id %q: %w", id, gorm.ErrRecordNotFound)
+		}
+
+		if err := tx.Model(&models.Booking{}).Where("trip_id = ?", id).
+			Updates(map[string]interface{}{"deleted_at": now, "version": gorm.Expr("version + 1")}).Error; err != nil {
+			return fmt.Errorf("persistence: failed to delete bookings of trip %q: %w", id, err)
+		}
+
+		return nil
+	})
+}
+
+// GetDeletedTripByID looks past the soft-delete scope for a deleted trip.
+func (r *RepositoryPg) GetDeletedTripByID(ctx context.Context, id string) (*models.Trip, error) {
+	var trip models.Trip
+
+	if err := r.gormDB.WithContext(ctx).Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&trip, "id = ? AND deleted_at IS NOT NULL", id).Error; err != nil {
+		return nil, fmt.Errorf("persistence: failed to get deleted trip with id %q: %w", id, err)
 	}
 
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("persistence: no trip found with id %q", id)
+	return &trip, nil
+}
+
+// RestoreTrip clears deleted_at on the trip and on the bookings that share
+// its deletion instant, bumping their versions; bookings deleted at another
+// time stay deleted.
+func (r *RepositoryPg) RestoreTrip(ctx context.Context, id string) (*models.Trip, error) {
+	err := r.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var trip models.Trip
+		if err := tx.Unscoped().First(&trip, "id = ? AND deleted_at IS NOT NULL", id).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&models.Booking{}).
+			Where("trip_id = ? AND deleted_at = ?", id, trip.DeletedAt).
+			Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Model(&models.Trip{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("persistence: failed to restore trip with id %q: %w", id, err)
+	}
+
+	return r.GetTripByID(ctx, id)
+}
+
+// PurgeDeletedTrips locks the expired trips, then hard-deletes in
+// foreign-key order: bookings (their transitions cascade), then trips.
+func (r *RepositoryPg) PurgeDeletedTrips(ctx context.Context, before time.Time) ([]string, error) {
+	var purged []string
+
+	err := r.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Trip{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("deleted_at < ?", before).
+			Pluck("id", &purged).Error; err != nil {
+			return err
+		}
+
+		if len(purged) == 0 {
+			return nil
+		}
+
+		if err := tx.Unscoped().Where("trip_id IN ?", purged).Delete(&models.Booking{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Where("id IN ?", purged).Delete(&models.Trip{}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("persistence: failed to purge deleted trips: %w", err)
 	}
 
-	return nil
+	return purged, nil
 }
 
-// SearchTrips applies the non-zero fields in params as WHERE filters.
+// CompleteEndedTrips locks every finished confirmed trip and moves them all
+// to completed in a single UPDATE.
+func (r *RepositoryPg) CompleteEndedTrips(ctx context.Context, now time.Time) ([]string, error) {
+	var completed []string
+
+	err := r.gormDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Trip{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status = ? AND end_date < ?", models.TripStatusConfirmed, now).
+			Pluck("id", &completed).Error; err != nil {
+			return err
+		}
+
+		if len(completed) == 0 {
+			return nil
+		}
+
+		return tx.Model(&models.Trip{}).
+			Where("id IN ?", completed).
+			Updates(map[string]interface{}{
+				"status":     models.TripStatusCompleted,
+				"version":    gorm.Expr("version + 1"),
+				"updated_at": now,
+			}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("persistence: failed to complete ended trips: %w", err)
+	}
+
+	return completed, nil
+}
+
+// SearchTrips applies the non-zero fields in params as WHERE filters and
+// returns one page of matches, by default ordered by start_date ascending.
 // destination is a case-insensitive partial match; date fields are range filters.
-func (r *RepositoryPg) SearchTrips(params models.TripSearchParams) ([]models.Trip, error) {
-	query := r.gormDB.Model(&models.Trip{})
+func (r *RepositoryPg) SearchTrips(ctx context.Context, params models.TripSearchParams, page models.PageParams) (*models.Page[models.Trip], error) {
+	if len(params.UserIDs) == 0 && params.MemberID == "" {
+		return &models.Page[models.Trip]{Items: []models.Trip{}}, nil
+	}
+
+	query := r.gormDB.WithContext(ctx).Model(&models.Trip{}).Where(r.visibleTrips(params.UserIDs, params.MemberID))
 
 	if params.Destination != "" {
 		// ILIKE enables case-insensitive partial matching on destination.
@@ -110,10 +319,27 @@ func (r *RepositoryPg) SearchTrips(params models.TripSearchParams) ([]models.Tri
 		query = query.Where("end_date <= ?", params.EndDate)
 	}
 
-	var trips []models.Trip
-	if err := query.Order("start_date ASC").Find(&trips).Error; err != nil {
+	trips, err := paginate(query, page, tripSort, "start_date")
+	if err != nil {
 		return nil, fmt.Errorf("persistence: failed to search trips: %w", err)
 	}
 
 	return trips, nil
-}
\ No newline at end of file
+}
+
+// visibleTrips is a WHERE condition matching the trips owned by any of
+// userIDs or that memberID is a member of. Either may be empty, but not
+// both: callers return an empty page first.
+func (r *RepositoryPg) visibleTrips(userIDs []string, memberID string) *gorm.DB {
+	if memberID == "" {
+		return r.gormDB.Where("user_id IN ?", userIDs)
+	}
+
+	memberTrips := r.gormDB.Model(&models.TripMember{}).Select("trip_id").Where("user_id = ?", memberID)
+
+	if len(userIDs) == 0 {
+		return r.gormDB.Where("id IN (?)", memberTrips)
+	}
+
+	return r.gormDB.Where("user_id IN ?", userIDs).Or("id IN (?)", memberTrips)
+}